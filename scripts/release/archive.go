@@ -0,0 +1,81 @@
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ArchiveZip writes the tree below src into a zip file at zipPath, for the
+// documentation of a version to be distributed as a bundle. Entries keep
+// their path relative to src, with forward slashes, their permission bits
+// and modification time. Symlinks are stored as symlinks. It walks src like
+// CopyDir does, so archives of a tree are identical.
+func ArchiveZip(fsys FS, src, zipPath string, excludeDirs []string) (err error) {
+	src = filepath.Clean(src)
+	if info, err := fsys.Lstat(src); err != nil {
+		return fmt.Errorf("stat source %q: %w", src, err)
+	} else if !info.IsDir() {
+		return fmt.Errorf("source %q is not a directory", src)
+	}
+
+	f, err := os.Create(zipPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(zipPath)
+		}
+	}()
+
+	zw := zip.NewWriter(f)
+	err = walkTree(fsys, src, excludeDirs, func(path, rel string, info fs.FileInfo) error {
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		switch {
+		case info.IsDir():
+			header.Name += "/"
+		case info.Mode().IsRegular():
+			header.Method = zip.Deflate
+		}
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("archive %q: %w", path, err)
+		}
+
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			linkTarget, err := fsys.Readlink(path)
+			if err != nil {
+				return fmt.Errorf("readlink %q: %w", path, err)
+			}
+			_, err = io.WriteString(w, filepath.ToSlash(linkTarget))
+			return err
+		case info.Mode().IsRegular():
+			in, err := fsys.Open(path)
+			if err != nil {
+				return fmt.Errorf("open source file %q: %w", path, err)
+			}
+			defer in.Close()
+			if _, err := io.Copy(w, in); err != nil {
+				return fmt.Errorf("archive %q: %w", path, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		zw.Close()
+		return err
+	}
+	return zw.Close()
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"archive/zip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestArchiveZip(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	for _, dir := range []string{"docs/guide", "drafts"} {
+		if err := os.MkdirAll(filepath.Join(src, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]fs.FileMode{
+		"_index.md":           0644,
+		"docs/guide/page.md":  0600,
+		"docs/guide/setup.sh": 0755,
+		"drafts/wip.md":       0644,
+	}
+	for name, mode := range files {
+		path := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.WriteFile(path, []byte(name), mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("page.md", filepath.Join(src, "docs", "guide", "link.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	zipPath := filepath.Join(root, "docs.zip")
+	if err := ArchiveZip(osFS{}, src, zipPath, []string{"drafts"}); err != nil {
+		t.Fatalf("ArchiveZip() error = %v", err)
+	}
+
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	var names []string
+	for _, f := range r.File {
+		names = append(names, f.Name)
+		switch {
+		case f.Mode()&fs.ModeSymlink != 0:
+			if got := readZipEntry(t, f); got != "page.md" {
+				t.Errorf("%s target = %q, want page.md", f.Name, got)
+			}
+		case f.Mode().IsRegular():
+			if got := readZipEntry(t, f); got != f.Name {
+				t.Errorf("%s content = %q, want %q", f.Name, got, f.Name)
+			}
+			if got, want := f.Mode().Perm(), files[f.Name]; got != want {
+				t.Errorf("%s mode = %v, want %v", f.Name, got, want)
+			}
+		}
+	}
+	want := []string{"_index.md", "docs/", "docs/guide/", "docs/guide/link.md", "docs/guide/page.md", "docs/guide/setup.sh"}
+	if !slices.Equal(names, want) {
+		t.Errorf("entries = %v, want %v", names, want)
+	}
+}
+
+func TestArchiveZipMissingSource(t *testing.T) {
+	root := t.TempDir()
+	zipPath := filepath.Join(root, "docs.zip")
+	if err := ArchiveZip(osFS{}, filepath.Join(root, "missing"), zipPath, nil); err == nil {
+		t.Fatal("ArchiveZip() error = nil, want an error")
+	}
+	if _, err := os.Stat(zipPath); !os.IsNotExist(err) {
+		t.Errorf("zip file exists after a failure: %v", err)
+	}
+}
+
+func readZipEntry(t *testing.T, f *zip.File) string {
+	t.Helper()
+	rc, err := f.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
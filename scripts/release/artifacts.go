@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// planArtifact is the name of the plan written to the artifacts folder
+const planArtifact = "plan.json"
+
+// artifactPath returns where to write the artifact name: inside the
+// ArtifactsDir of the settings, which is created if needed, or name itself
+// when unset.
+func (s Settings) artifactPath(name string) (string, error) {
+	if s.ArtifactsDir == "" {
+		return name, nil
+	}
+	if err := s.mkdirGenerated(s.ArtifactsDir); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.ArtifactsDir, filepath.Base(name)), nil
+}
+
+// createArtifact creates the artifact name inside the ArtifactsDir of the
+// settings, with their file mode
+func (s Settings) createArtifact(name string) (*os.File, error) {
+	path, err := s.artifactPath(name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, s.fileMode())
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Chmod(s.fileMode()); err != nil {
+		f.Close()
+		return nil, err
+	}
+	printProgress("Writing %s\n", path)
+	return f, nil
+}
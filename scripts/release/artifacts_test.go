@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestArtifactsDir(t *testing.T) {
+	root := newTestRepo(t)
+	settings := Settings{ArtifactsDir: filepath.Join(t.TempDir(), "artifacts"), EmitJSON: true}
+
+	planFile, err := settings.createArtifact(planArtifact)
+	if err != nil {
+		t.Fatalf("createArtifact() error = %v", err)
+	}
+	defer planFile.Close()
+	if _, err := addRelease(AddOptions{Settings: settings, Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33", PlanOutput: planFile}); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	summary, err := settings.artifactPath(filepath.Join("some", "dir", "summary.md"))
+	if err != nil {
+		t.Fatalf("artifactPath() error = %v", err)
+	}
+	if want := filepath.Join(settings.ArtifactsDir, "summary.md"); summary != want {
+		t.Errorf("artifactPath() = %q, want %q", summary, want)
+	}
+
+	for _, name := range []string{planArtifact, "eso_versions.json"} {
+		info, err := os.Stat(filepath.Join(settings.ArtifactsDir, name))
+		if err != nil {
+			t.Errorf("artifact %s is missing: %v", name, err)
+		} else if info.Size() == 0 {
+			t.Errorf("artifact %s is empty", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "data", "eso_versions.json")); !os.IsNotExist(err) {
+		t.Errorf("JSON mirror was written next to the data file: %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// backupSuffix is appended to the name of a data file to name its backup
+const backupSuffix = ".bak"
+
+// backupVersions copies filename to its backup, when Backup is set. A data
+// file is only backed up once per run, so its backup holds the content it
+// had before the run.
+func (s Settings) backupVersions(filename string) error {
+	if !s.Backup || s.backups[filename] != "" {
+		return nil
+	}
+	content, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	backup := filename + backupSuffix
+	if err := s.writeGeneratedFile(backup, content); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", filename, err)
+	}
+	if s.backups != nil {
+		s.backups[filename] = backup
+	}
+	printProgress("Backed up %s to %s\n", filename, backup)
+	return nil
+}
+
+// removeBackups removes the backups of the run, when BackupCleanup is set
+func (s Settings) removeBackups() error {
+	if !s.BackupCleanup {
+		return nil
+	}
+	for filename, backup := range s.backups {
+		if err := os.Remove(backup); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		delete(s.backups, filename)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// backupSettings returns the settings of a run backing up the data files
+func backupSettings(cleanup bool) Settings {
+	return Settings{Backup: true, BackupCleanup: cleanup, backups: map[string]string{}}
+}
+
+func TestAddReleaseBackup(t *testing.T) {
+	settings := backupSettings(false)
+	root := newTestRepo(t)
+	dataFile := filepath.Join(root, "data", "eso_versions.toml")
+	original, err := os.ReadFile(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := addRelease(AddOptions{Settings: settings, Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33"}); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	// A second change of the run keeps the content from before the run
+	if err := replaceRelease(settings, root, "eso", "v0.15.0", ReplaceOptions{ReleaseDate: "2025-06-01"}); err != nil {
+		t.Fatalf("replaceRelease() error = %v", err)
+	}
+
+	backup, err := os.ReadFile(dataFile + backupSuffix)
+	if err != nil {
+		t.Fatalf("backup not written: %v", err)
+	}
+	if string(backup) != string(original) {
+		t.Errorf("backup =\n%s\nwant the content before the run:\n%s", backup, original)
+	}
+	if err := settings.removeBackups(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dataFile + backupSuffix); err != nil {
+		t.Errorf("backup removed without backupCleanup: %v", err)
+	}
+}
+
+func TestRemoveBackups(t *testing.T) {
+	settings := backupSettings(true)
+	root := newTestRepo(t)
+	dataFile := filepath.Join(root, "data", "eso_versions.toml")
+
+	if err := replaceRelease(settings, root, "eso", "v0.14.0", ReplaceOptions{ReleaseDate: "2025-02-01"}); err != nil {
+		t.Fatalf("replaceRelease() error = %v", err)
+	}
+	if _, err := os.Stat(dataFile + backupSuffix); err != nil {
+		t.Fatalf("backup not written: %v", err)
+	}
+	if err := settings.removeBackups(); err != nil {
+		t.Fatalf("removeBackups() error = %v", err)
+	}
+	if _, err := os.Stat(dataFile + backupSuffix); !os.IsNotExist(err) {
+		t.Errorf("backup still exists after cleanup: %v", err)
+	}
+}
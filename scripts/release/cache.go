@@ -0,0 +1,106 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// defaultGoModCacheTTL is how long a cached go.mod is used by default
+const defaultGoModCacheTTL = time.Hour
+
+// goModCacheFile returns the file caching the go.mod fetched from url. The
+// default folder is per user, rather than shared in the temporary directory
+// where anyone could plant go.mod files deciding the tested k8s versions.
+func (s Settings) goModCacheFile(url string) (string, error) {
+	dir := s.CacheDir
+	if dir == "" {
+		userDir, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(userDir, "release-go-mod-cache")
+	}
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".mod"), nil
+}
+
+// checkPrivateDir fails unless dir is a folder, not a symlink, owned by the
+// current user. Access for the group and others is removed, so that only
+// the user can read or plant the files it holds.
+func checkPrivateDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	if !ownedByCurrentUser(info) {
+		return fmt.Errorf("%s is owned by another user", dir)
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		return os.Chmod(dir, 0700)
+	}
+	return nil
+}
+
+// goModCacheEnabled reports whether fetched go.mod files are cached
+func (s Settings) goModCacheEnabled() bool {
+	return !s.NoCache && s.CacheTTL > 0
+}
+
+// readCachedGoMod returns the go.mod cached for url, if it was fetched
+// less than CacheTTL ago
+func (s Settings) readCachedGoMod(url string) ([]byte, bool) {
+	if !s.goModCacheEnabled() {
+		return nil, false
+	}
+	file, err := s.goModCacheFile(url)
+	if err != nil {
+		return nil, false
+	}
+	if err := checkPrivateDir(filepath.Dir(file)); err != nil {
+		if !os.IsNotExist(err) {
+			logWarning("not using the go.mod cache: %v", err)
+		}
+		return nil, false
+	}
+	info, err := os.Stat(file)
+	if err != nil || time.Since(info.ModTime()) >= s.CacheTTL {
+		return nil, false
+	}
+	body, err := os.ReadFile(file)
+	if err != nil {
+		return nil, false
+	}
+	printProgress("Using the go.mod of %s cached in %s\n", url, file)
+	return body, true
+}
+
+// writeCachedGoMod caches the go.mod fetched from url. Failing to cache it
+// does not fail the run.
+func (s Settings) writeCachedGoMod(url string, body []byte) {
+	if !s.goModCacheEnabled() {
+		return
+	}
+	file, err := s.goModCacheFile(url)
+	if err != nil {
+		logWarning("cannot cache the go.mod of %s: %v", url, err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		logWarning("cannot cache the go.mod of %s: %v", url, err)
+		return
+	}
+	if err := checkPrivateDir(filepath.Dir(file)); err != nil {
+		logWarning("cannot cache the go.mod of %s: %v", url, err)
+		return
+	}
+	if err := os.WriteFile(file, body, 0600); err != nil {
+		logWarning("cannot cache the go.mod of %s: %v", url, err)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// cacheSettings returns the settings of a run caching the go.mod files in
+// a temporary directory
+func cacheSettings(t *testing.T, ttl time.Duration) Settings {
+	return Settings{CacheDir: t.TempDir(), CacheTTL: ttl}
+}
+
+func TestFetchGoModCache(t *testing.T) {
+	const url = "https://example.com/go.mod"
+	fake := useFakeTransport(t, sampleGoMod)
+	settings := cacheSettings(t, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		body, err := settings.fetchGoMod(httpClient, url)
+		if err != nil {
+			t.Fatalf("fetchGoMod() error = %v", err)
+		}
+		if string(body) != sampleGoMod {
+			t.Errorf("fetchGoMod() = %q, want the served go.mod", body)
+		}
+	}
+	if len(fake.requested) != 1 {
+		t.Errorf("requested %v, want a single request within the TTL", fake.requested)
+	}
+
+	settings.NoCache = true
+	if _, err := settings.fetchGoMod(httpClient, url); err != nil {
+		t.Fatalf("fetchGoMod() with --no-cache error = %v", err)
+	}
+	if len(fake.requested) != 2 {
+		t.Errorf("requested %v, want --no-cache to fetch again", fake.requested)
+	}
+}
+
+func TestFetchGoModCacheExpired(t *testing.T) {
+	const url = "https://example.com/go.mod"
+	fake := useFakeTransport(t, sampleGoMod)
+	settings := cacheSettings(t, time.Hour)
+
+	if _, err := settings.fetchGoMod(httpClient, url); err != nil {
+		t.Fatalf("fetchGoMod() error = %v", err)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	file, err := settings.goModCacheFile(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(file, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := settings.fetchGoMod(httpClient, url); err != nil {
+		t.Fatalf("fetchGoMod() error = %v", err)
+	}
+	if len(fake.requested) != 2 {
+		t.Errorf("requested %v, want an expired entry to be fetched again", fake.requested)
+	}
+}
+
+func TestGoModCachePrivate(t *testing.T) {
+	const url = "https://example.com/go.mod"
+	useFakeTransport(t, sampleGoMod)
+	settings := cacheSettings(t, time.Hour)
+	// A folder open to everyone is made private before caching into it
+	settings.CacheDir = filepath.Join(settings.CacheDir, "shared")
+	if err := os.Mkdir(settings.CacheDir, 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(settings.CacheDir, 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := settings.fetchGoMod(httpClient, url); err != nil {
+		t.Fatalf("fetchGoMod() error = %v", err)
+	}
+	file, err := settings.goModCacheFile(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]os.FileMode{settings.CacheDir: 0700, file: 0600} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Errorf("%s mode = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestGoModCacheRefusesUntrustedDir(t *testing.T) {
+	const url = "https://example.com/go.mod"
+	const planted = "module planted\n\nrequire k8s.io/client-go v0.20.0\n"
+
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, dir string)
+	}{
+		{
+			name: "symlink",
+			setup: func(t *testing.T, dir string) {
+				target := t.TempDir()
+				if err := os.Symlink(target, dir); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "owned by another user",
+			setup: func(t *testing.T, dir string) {
+				if os.Getuid() != 0 {
+					t.Skip("changing the owner of a folder needs root")
+				}
+				if err := os.Mkdir(dir, 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Chown(dir, 65534, 65534); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := useFakeTransport(t, sampleGoMod)
+			settings := cacheSettings(t, time.Hour)
+			settings.CacheDir = filepath.Join(settings.CacheDir, "cache")
+			tt.setup(t, settings.CacheDir)
+			file, err := settings.goModCacheFile(url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(file, []byte(planted), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			body, err := settings.fetchGoMod(httpClient, url)
+			if err != nil {
+				t.Fatalf("fetchGoMod() error = %v", err)
+			}
+			if string(body) != sampleGoMod || len(fake.requested) != 1 {
+				t.Errorf("fetchGoMod() = %q after %d requests, want the served go.mod rather than the planted one", body, len(fake.requested))
+			}
+		})
+	}
+}
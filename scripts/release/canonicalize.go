@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+func handleCanonicalize(settings Settings, project string, normalizeStrings bool) {
+	if project == "" {
+		fmt.Print("Missing project\n")
+		printReleaseUsage()
+		os.Exit(1)
+	}
+
+	migrate := canonicalizeVersions
+	if normalizeStrings {
+		migrate = func(data *VersionsData) {
+			canonicalizeVersions(data)
+			normalizeVersionStrings(data)
+		}
+	}
+	if err := migrateDataFile(settings, "", project, "Canonicalized", migrate); err != nil {
+		exitWithError(err)
+	}
+}
+
+func handleNormalizeVersionStrings(settings Settings, project string) {
+	if project == "" {
+		fmt.Print("Missing project\n")
+		printReleaseUsage()
+		os.Exit(1)
+	}
+
+	if err := migrateDataFile(settings, "", project, "Normalized the version strings of", normalizeVersionStrings); err != nil {
+		exitWithError(err)
+	}
+}
+
+// canonicalizeDataFile rewrites the data file of project in its canonical
+// form, or leaves it untouched when it already is
+func canonicalizeDataFile(settings Settings, root string, project string) error {
+	return migrateDataFile(settings, root, project, "Canonicalized", canonicalizeVersions)
+}
+
+// migrateDataFile rewrites the data file of project through migrate, or
+// leaves it untouched when migrate changes nothing. done describes the
+// change in the progress output.
+func migrateDataFile(settings Settings, root string, project string, done string, migrate func(*VersionsData)) error {
+	if err := validateProject(project); err != nil {
+		return err
+	}
+	dataFile := settings.dataFilePath(root, project)
+
+	unlock, err := lockDataFile(dataFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	versions, err := readVersions(dataFile)
+	if err != nil {
+		return err
+	}
+	migrate(versions)
+
+	content, err := settings.encodeVersions(versionsFormat(dataFile), versions)
+	if err != nil {
+		return err
+	}
+	existing, err := os.ReadFile(dataFile)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(bytes.ReplaceAll(existing, []byte("\r\n"), []byte("\n")), content) {
+		fmt.Printf("%s is unchanged\n", dataFile)
+		return nil
+	}
+	if err := settings.writeVersions(dataFile, versions); err != nil {
+		return err
+	}
+	printStep("%s %s", done, dataFile)
+	return nil
+}
+
+// canonicalizeVersions normalizes versions without changing what they
+// mean: versions are sorted newest first, tags get their leading v, the
+// latest label baked into human readable versions is dropped since the
+// templates append it, and tested k8s versions are trimmed, written as
+// v1.X and deduplicated. Tags that are not semver sort last.
+func canonicalizeVersions(data *VersionsData) {
+	labels := []string{data.latestLabel(), defaultLatestLabel}
+	for i := range data.Versions {
+		v := &data.Versions[i]
+		v.Tag = normalizeVersion(strings.TrimSpace(v.Tag))
+		v.Version = stripLatestLabel(v.Version, labels...)
+		v.ReleaseDate = strings.TrimSpace(v.ReleaseDate)
+		v.EndOfLife = strings.TrimSpace(v.EndOfLife)
+
+		if v.TestedK8sVersions == nil {
+			continue
+		}
+		tested := []string{}
+		for _, version := range v.TestedK8sVersions {
+			version = normalizeK8sVersion(strings.TrimSpace(version))
+			if version != "" && !slices.Contains(tested, version) {
+				tested = append(tested, version)
+			}
+		}
+		v.TestedK8sVersions = tested
+	}
+	sort.SliceStable(data.Versions, func(i, j int) bool {
+		return compareVersions(data.Versions[i].Tag, data.Versions[j].Tag) > 0
+	})
+}
+
+// normalizeVersionStrings cleans the human readable version of each
+// version: stray spaces and baked-in latest labels are dropped, as the
+// templates append the label to the latest version only. The full or
+// major.minor form of its tag, as --display-version and --canonical-version
+// write, is kept; any other version string is rebuilt from the tag.
+// Versions without one keep displaying their tag.
+func normalizeVersionStrings(data *VersionsData) {
+	labels := []string{data.latestLabel(), defaultLatestLabel}
+	for i := range data.Versions {
+		v := &data.Versions[i]
+		version := stripLatestLabel(v.Version, labels...)
+		if version == "" {
+			v.Version = ""
+			continue
+		}
+		tag := normalizeVersion(strings.TrimSpace(v.Tag))
+		switch normalizeVersion(version) {
+		case tag, semver.MajorMinor(tag):
+			v.Version = version
+		default:
+			v.Version = tag
+		}
+	}
+}
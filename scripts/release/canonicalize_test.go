@@ -0,0 +1,107 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCanonicalizeDataFile(t *testing.T) {
+	root := t.TempDir()
+	dataFile := filepath.Join(root, "data", "eso_versions.toml")
+	messy, err := os.ReadFile(filepath.Join("testdata", "canonicalize", "messy.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dataFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dataFile, messy, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := canonicalizeDataFile(Settings{}, root, "eso"); err != nil {
+		t.Fatalf("canonicalizeDataFile() error = %v", err)
+	}
+	got, err := os.ReadFile(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden, err := os.ReadFile(filepath.Join("testdata", "canonicalize", "canonical.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(golden) {
+		t.Errorf("canonical data file =\n%s\nwant the golden file:\n%s", got, golden)
+	}
+
+	// The canonical form is stable
+	if err := canonicalizeDataFile(Settings{}, root, "eso"); err != nil {
+		t.Fatalf("canonicalizeDataFile() again error = %v", err)
+	}
+	if again, err := os.ReadFile(dataFile); err != nil || string(again) != string(golden) {
+		t.Errorf("second run changed the data file:\n%s", again)
+	}
+}
+
+func TestNormalizeVersionStrings(t *testing.T) {
+	data := &VersionsData{
+		LatestLabel: "[current]",
+		Versions: []Version{
+			{Tag: "v0.15.0", Version: "v0.15.0 [current]", Latest: true},
+			{Tag: "v0.14.1", Version: " v0.14.1 (latest) "},
+			{Tag: "v0.14.0", Version: "v0.14"},
+			{Tag: "v0.13.3", Version: " v0.13  "},
+			{Tag: "0.12.0", Version: "0.12.0"},
+			{Tag: "v0.11.2", Version: "v0.11.1"},
+			{Tag: "v0.10.0", Version: "ten"},
+			{Tag: "v0.9.0", Version: "  "},
+			{Tag: "v0.8.0"},
+		},
+	}
+	normalizeVersionStrings(data)
+
+	// Display versions written by --display-version and --canonical-version
+	// are kept, stale or unrelated ones are rebuilt from the tag
+	want := []string{"v0.15.0", "v0.14.1", "v0.14", "v0.13", "0.12.0", "v0.11.2", "v0.10.0", "", ""}
+	for i, v := range data.Versions {
+		if v.Version != want[i] {
+			t.Errorf("version of %s = %q, want %q", v.Tag, v.Version, want[i])
+		}
+	}
+}
+
+func TestMigrateDataFileNormalizeVersionStrings(t *testing.T) {
+	root := t.TempDir()
+	dataFile := filepath.Join(root, "data", "eso_versions.toml")
+	if err := os.MkdirAll(filepath.Dir(dataFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	messy := `[[versions]]
+  tag = "v0.14.1"
+  version = "v0.14.1  (latest)"
+  latest = true
+
+[[versions]]
+  tag = "v0.14.0"
+  version = " v0.14 "
+  latest = false
+`
+	if err := os.WriteFile(dataFile, []byte(messy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrateDataFile(Settings{}, root, "eso", "Normalized", normalizeVersionStrings); err != nil {
+		t.Fatalf("migrateDataFile() error = %v", err)
+	}
+	versions, err := readVersions(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"v0.14.1": "v0.14.1", "v0.14.0": "v0.14"}
+	for _, v := range versions.Versions {
+		if v.Version != want[v.Tag] {
+			t.Errorf("version of %s = %q, want %q", v.Tag, v.Version, want[v.Tag])
+		}
+	}
+}
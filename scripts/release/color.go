@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+// ANSI colors of the output
+const (
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorRed    = "\033[31m"
+	colorReset  = "\033[0m"
+)
+
+// noColorEnv disables colors when set, see https://no-color.org
+const noColorEnv = "NO_COLOR"
+
+var (
+	// stdoutColor colors the steps printed on stdout
+	stdoutColor bool
+	// stderrColor colors the warnings and errors logged on stderr
+	stderrColor bool
+)
+
+// isTerminal reports whether f is a terminal rather than a file or a pipe
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorEnabled reports whether the output to f is colored: only on a
+// terminal, unless disabled (e.g. --quiet or --json) or NO_COLOR is set
+func colorEnabled(f *os.File, disabled bool) bool {
+	return !disabled && os.Getenv(noColorEnv) == "" && isTerminal(f)
+}
+
+// setupColors enables the colors of stdout and stderr when they are
+// terminals
+func setupColors(disabled bool) {
+	stdoutColor = colorEnabled(os.Stdout, disabled)
+	stderrColor = colorEnabled(os.Stderr, disabled)
+}
+
+// colorize wraps s in color when enabled
+func colorize(enabled bool, color string, s string) string {
+	if !enabled {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// progressOut receives the progress of a run: stdout, stderr when stdout
+// carries JSON output, or nothing with --quiet
+var progressOut io.Writer = os.Stdout
+
+// setupProgress sets where the progress of a run is printed
+func setupProgress(quiet bool, asJSON bool) {
+	switch {
+	case quiet:
+		progressOut = io.Discard
+	case asJSON:
+		progressOut = os.Stderr
+	default:
+		progressOut = os.Stdout
+	}
+}
+
+// printProgress prints a progress message of a run
+func printProgress(format string, args ...any) {
+	fmt.Fprintf(progressOut, format, args...)
+}
+
+// printStep prints a completed step of a run, in green on a terminal
+func printStep(format string, args ...any) {
+	fmt.Fprintln(progressOut, colorize(stdoutColor, colorGreen, fmt.Sprintf(format, args...)))
+}
+
+// logWarning logs a warning, in yellow on a terminal
+func logWarning(format string, args ...any) {
+	log.Print(colorize(stderrColor, colorYellow, "Warning: "+fmt.Sprintf(format, args...)))
+}
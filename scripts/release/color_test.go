@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestColorEnabledPipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close(); w.Close() })
+	t.Setenv(noColorEnv, "")
+
+	if isTerminal(w) {
+		t.Error("isTerminal() of a pipe = true, want false")
+	}
+	if colorEnabled(w, false) {
+		t.Error("colorEnabled() of a pipe = true, want false")
+	}
+}
+
+func TestColorDisabled(t *testing.T) {
+	// Only a terminal would enable colors, which tests do not run on,
+	// so the disabling cases are checked on the colors being reset
+	originalStdout, originalStderr := stdoutColor, stderrColor
+	t.Cleanup(func() { stdoutColor, stderrColor = originalStdout, originalStderr })
+
+	stdoutColor, stderrColor = true, true
+	t.Setenv(noColorEnv, "1")
+	setupColors(false)
+	if stdoutColor || stderrColor {
+		t.Error("colors enabled with NO_COLOR set")
+	}
+
+	stdoutColor, stderrColor = true, true
+	t.Setenv(noColorEnv, "")
+	setupColors(true)
+	if stdoutColor || stderrColor {
+		t.Error("colors enabled with --quiet or --json")
+	}
+}
+
+func TestLogWarningColor(t *testing.T) {
+	originalStderr := stderrColor
+	t.Cleanup(func() { stderrColor = originalStderr })
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	stderrColor = false
+	logWarning("content source %s has no files", "unreleased")
+	if got := buf.String(); strings.Contains(got, "\033[") || !strings.Contains(got, "Warning: content source unreleased has no files") {
+		t.Errorf("logWarning() without colors = %q, want the plain warning", got)
+	}
+
+	buf.Reset()
+	stderrColor = true
+	logWarning("late")
+	if got := buf.String(); !strings.Contains(got, colorYellow+"Warning: late"+colorReset) {
+		t.Errorf("logWarning() with colors = %q, want it in yellow", got)
+	}
+}
+
+func TestSetupProgressQuiet(t *testing.T) {
+	useFakeTransport(t, sampleGoMod)
+	root := newTestRepo(t)
+	stdout, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdout.Close()
+	originalStdout, originalProgress := os.Stdout, progressOut
+	t.Cleanup(func() { os.Stdout, progressOut = originalStdout, originalProgress })
+	os.Stdout = stdout
+
+	setupProgress(true, false)
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33", Quiet: true}); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	if got, _ := os.ReadFile(stdout.Name()); len(got) != 0 {
+		t.Errorf("progress printed with --quiet: %q", got)
+	}
+
+	setupProgress(false, false)
+	printStep("Done")
+	if got, _ := os.ReadFile(stdout.Name()); string(got) != "Done\n" {
+		t.Errorf("progress without --quiet = %q, want %q", got, "Done\n")
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// K8sComparison is the change of the tested k8s versions between two
+// versions of a project
+type K8sComparison struct {
+	From    string   `json:"from"`
+	To      string   `json:"to"`
+	Added   []string `json:"k8s_added"`
+	Removed []string `json:"k8s_removed"`
+	// LatestChanged is set when only one of From and To is the latest
+	LatestChanged bool `json:"latest_changed"`
+	// Latest is the latest of From and To, empty when neither is
+	Latest string `json:"latest,omitempty"`
+}
+
+func handleCompare(settings Settings, project string, from string, to string, asJSON bool) {
+	if project == "" || from == "" || to == "" {
+		fmt.Print("Missing project, from or to version\n")
+		printReleaseUsage()
+		os.Exit(1)
+	}
+	if err := validateProject(project); err != nil {
+		exitWithError(err)
+	}
+
+	versions, err := readVersions(settings.dataFilePath("", project))
+	if err != nil {
+		exitWithError(err)
+	}
+	comparison, err := compareK8sVersions(versions.Versions, from, to)
+	if err != nil {
+		exitWithError(err)
+	}
+	if err := printK8sComparison(os.Stdout, comparison, asJSON); err != nil {
+		exitWithError(err)
+	}
+}
+
+// findVersion returns the version of tag. A major.minor tag (e.g. v0.14)
+// without an exact match designates the highest patch of that minor.
+func findVersion(versions []Version, tag string) (Version, error) {
+	tag = normalizeVersion(tag)
+	if !semver.IsValid(tag) {
+		return Version{}, invalidf("Invalid semver tag: %s", tag)
+	}
+	for _, v := range versions {
+		if normalizeVersion(v.Tag) == tag {
+			return v, nil
+		}
+	}
+	var found *Version
+	majorMinorOnly := tag == semver.MajorMinor(tag)
+	for i, v := range versions {
+		if !semver.IsValid(normalizeVersion(v.Tag)) {
+			continue
+		}
+		matches := compareVersions(v.Tag, tag) == 0
+		if majorMinorOnly {
+			matches = semver.MajorMinor(normalizeVersion(v.Tag)) == tag && semver.Prerelease(normalizeVersion(v.Tag)) == ""
+		}
+		if matches && (found == nil || compareVersions(v.Tag, found.Tag) > 0) {
+			found = &versions[i]
+		}
+	}
+	if found == nil {
+		return Version{}, invalidf("Version %s not found", tag)
+	}
+	return *found, nil
+}
+
+// compareK8sVersions compares the tested k8s versions of the versions
+// from and to, e.g. for a migration guide
+func compareK8sVersions(versions []Version, from string, to string) (*K8sComparison, error) {
+	fromVersion, err := findVersion(versions, from)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, err := findVersion(versions, to)
+	if err != nil {
+		return nil, err
+	}
+	comparison := &K8sComparison{From: fromVersion.Tag, To: toVersion.Tag, LatestChanged: fromVersion.Latest != toVersion.Latest}
+	comparison.Added, comparison.Removed = diffK8sVersions(fromVersion.TestedK8sVersions, toVersion.TestedK8sVersions)
+	switch {
+	case toVersion.Latest:
+		comparison.Latest = toVersion.Tag
+	case fromVersion.Latest:
+		comparison.Latest = fromVersion.Tag
+	}
+	return comparison, nil
+}
+
+// printK8sComparison outputs the comparison, as text or JSON
+func printK8sComparison(w io.Writer, c *K8sComparison, asJSON bool) error {
+	if asJSON {
+		out, err := json.MarshalIndent(c, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", out)
+		return err
+	}
+	list := func(versions []string) string {
+		if len(versions) == 0 {
+			return "-"
+		}
+		return strings.Join(versions, ", ")
+	}
+	latest := "no"
+	if c.LatestChanged {
+		latest = fmt.Sprintf("yes, %s is the latest", c.Latest)
+	}
+	_, err := fmt.Fprintf(w, "%s -> %s\nk8s added:      %s\nk8s removed:    %s\nlatest changed: %s\n",
+		c.From, c.To, list(c.Added), list(c.Removed), latest)
+	return err
+}
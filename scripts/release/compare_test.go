@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompareK8sVersions(t *testing.T) {
+	root := setupFixture(t)
+	versions, err := readVersions(Settings{}.dataFilePath(root, "eso"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := compareK8sVersions(versions.Versions, "v0.13", "v0.14")
+	if err != nil {
+		t.Fatalf("compareK8sVersions() error = %v", err)
+	}
+	want := &K8sComparison{From: "v0.13.0", To: "v0.14.1", Added: []string{"v1.32"}, Removed: []string{"v1.30"}, LatestChanged: true, Latest: "v0.14.1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("compareK8sVersions() = %+v, want %+v", got, want)
+	}
+
+	var out strings.Builder
+	if err := printK8sComparison(&out, got, false); err != nil {
+		t.Fatal(err)
+	}
+	if wantText := "v0.13.0 -> v0.14.1\nk8s added:      v1.32\nk8s removed:    v1.30\nlatest changed: yes, v0.14.1 is the latest\n"; out.String() != wantText {
+		t.Errorf("printK8sComparison() = %q, want %q", out.String(), wantText)
+	}
+
+	got, err = compareK8sVersions(versions.Versions, "v0.14.0", "v0.14.1")
+	if err != nil {
+		t.Fatalf("compareK8sVersions() error = %v", err)
+	}
+	if len(got.Added) != 0 || len(got.Removed) != 0 || !got.LatestChanged {
+		t.Errorf("compareK8sVersions() of the patches = %+v, want no k8s change", got)
+	}
+
+	if _, err := compareK8sVersions(versions.Versions, "v0.12", "v0.14"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("compareK8sVersions() with an unknown version error = %v, want ErrInvalid", err)
+	}
+}
+
+func TestFindVersion(t *testing.T) {
+	versions := []Version{{Tag: "v0.15.0-rc.1"}, {Tag: "v0.14.1"}, {Tag: "v0.14"}, {Tag: "v0.13.0"}}
+	tests := []struct{ tag, want string }{
+		{tag: "v0.14", want: "v0.14"},
+		{tag: "v0.14.0", want: "v0.14"},
+		{tag: "0.13", want: "v0.13.0"},
+		{tag: "v0.15.0-rc.1", want: "v0.15.0-rc.1"},
+	}
+	for _, tt := range tests {
+		got, err := findVersion(versions, tt.tag)
+		if err != nil {
+			t.Fatalf("findVersion(%q) error = %v", tt.tag, err)
+		}
+		if got.Tag != tt.want {
+			t.Errorf("findVersion(%q) = %s, want %s", tt.tag, got.Tag, tt.want)
+		}
+	}
+	if _, err := findVersion(versions, "v0.15"); err == nil {
+		t.Error("findVersion(v0.15) succeeded, want pre-releases left out of major.minor lookups")
+	}
+}
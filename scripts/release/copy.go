@@ -1,72 +1,234 @@
 package main
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
-	"time"
+	"strings"
 )
 
+// SymlinkPolicy decides what CopyDir does with symlinks whose target
+// escapes the source tree (e.g. "../../secret")
+type SymlinkPolicy int
+
+const (
+	// SymlinkKeep reproduces escaping symlinks as they are
+	SymlinkKeep SymlinkPolicy = iota
+	// SymlinkStrict refuses to copy escaping symlinks
+	SymlinkStrict
+	// SymlinkRewrite rewrites escaping symlinks so that, relative to their
+	// new location, they still point to the same target
+	SymlinkRewrite
+)
+
+// CopyOptions tunes the behavior of CopyDirWithOptions.
+// The zero value matches CopyDir.
+type CopyOptions struct {
+	EscapingSymlinks SymlinkPolicy
+	// Dereference copies the content symlinks point to instead of
+	// recreating them: directory symlinks become real directories.
+	// Dangling and circular symlinks are errors.
+	Dereference bool
+	// Progress, when set, is called with the number of files copied so far
+	// and the total (counted beforehand), from the calling goroutine.
+	// The first call reports 0 copied and the last one copied == total.
+	// A dereferenced directory symlink counts as a single file.
+	Progress func(copied, total int)
+	// FS is the filesystem to copy on, defaults to the OS one
+	FS FS
+	// SkipUnchanged does not rewrite destination files having the same
+	// size and modification time as their source
+	SkipUnchanged bool
+	// CompareContent additionally requires identical content (SHA-256)
+	// for SkipUnchanged to skip a file
+	CompareContent bool
+	// Stats, when set, counts the files and bytes copied
+	Stats *CopyStats
+	// ExcludeDirs are directories of the source, relative to it, skipped
+	// along with their content: they are not even created. With
+	// Dereference, they can be or lie below directory symlinks.
+	ExcludeDirs []string
+}
+
+// isExcludedDir reports whether path, below src, is one of excludeDirs
+func isExcludedDir(src, path string, excludeDirs []string) bool {
+	rel, err := filepath.Rel(src, path)
+	if err != nil {
+		return false
+	}
+	for _, dir := range excludeDirs {
+		if filepath.Clean(dir) == rel {
+			return true
+		}
+	}
+	return false
+}
+
+// excludedDirsBelow returns the directories of excludeDirs below dir,
+// relative to it
+func excludedDirsBelow(dir string, excludeDirs []string) []string {
+	var below []string
+	for _, excluded := range excludeDirs {
+		rel, err := filepath.Rel(dir, filepath.Clean(excluded))
+		if err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			below = append(below, rel)
+		}
+	}
+	return below
+}
+
+// CopyStats counts what a copy wrote
+type CopyStats struct {
+	Files int
+	Bytes int64
+}
+
+// add counts a copied file of size bytes
+func (s *CopyStats) add(size int64) {
+	if s == nil {
+		return
+	}
+	s.Files++
+	s.Bytes += size
+}
+
 // CopyDir copies the contents of the directory src into the directory dst.
 // If dst does not exist it will be created with the same permission bits as src.
 // Behavior:
 // - copies files and subdirectories recursively
 // - preserves file permission bits and modification times
 // - reproduces symlinks as symlinks (does not follow them)
+// - is deterministic: entries are copied in lexical order whatever order
+// the filesystem lists them in, so copies of a tree are identical and
+// report the same progress
 // Usage example:
 //
 //	err := CopyDir("unreleased", "versionX")
 //	if err != nil { log.Fatalf("copy failed: %v", err) }
 func CopyDir(src, dst string) error {
-	src = filepath.Clean(src)
-	dst = filepath.Clean(dst)
+	return CopyDirWithOptions(src, dst, CopyOptions{})
+}
 
-	srcInfo, err := os.Lstat(src)
-	if err != nil {
-		return fmt.Errorf("stat source %q: %w", src, err)
-	}
-	if !srcInfo.IsDir() {
-		return fmt.Errorf("source %q is not a directory", src)
+// CopyDirWithOptions is CopyDir with tunable behavior, see CopyOptions.
+func CopyDirWithOptions(src, dst string, opts CopyOptions) error {
+	fsys := opts.FS
+	if fsys == nil {
+		fsys = osFS{}
 	}
+	src = filepath.Clean(src)
+	dst = filepath.Clean(dst)
 
-	// create destination root with same permissions as src
-	if err := os.MkdirAll(dst, srcInfo.Mode()); err != nil {
-		return fmt.Errorf("create destination %q: %w", dst, err)
+	var tick func()
+	if opts.Progress != nil {
+		total, err := countFiles(fsys, src, opts.ExcludeDirs)
+		if err != nil {
+			return err
+		}
+		copied := 0
+		opts.Progress(copied, total)
+		tick = func() {
+			copied++
+			opts.Progress(copied, total)
+		}
 	}
+	return copyTree(fsys, src, dst, opts, map[string]bool{}, tick)
+}
 
-	// Walk the source tree
-	return filepath.WalkDir(src, func(path string, d fs.DirEntry, walkErr error) error {
+// walkTree walks the tree below src, in lexical order, calling fn with
+// each entry but src itself, its path relative to src and its info
+// (symlinks are not followed). The directories of excludeDirs are skipped
+// along with their content. fn may return filepath.SkipDir for a directory.
+func walkTree(fsys FS, src string, excludeDirs []string, fn func(path, rel string, info fs.FileInfo) error) error {
+	return walkDir(fsys, src, func(path string, d fs.DirEntry, walkErr error) error {
 		if walkErr != nil {
 			return walkErr
 		}
-
 		rel, err := filepath.Rel(src, path)
 		if err != nil {
 			return err
 		}
-		// skip the root; it's already created
 		if rel == "." {
 			return nil
 		}
-
-		targetPath := filepath.Join(dst, rel)
-
+		if d.IsDir() && isExcludedDir(src, path, excludeDirs) {
+			return filepath.SkipDir
+		}
 		info, err := d.Info()
 		if err != nil {
 			return err
 		}
+		return fn(path, rel, info)
+	})
+}
+
+// countFiles returns the number of non directory entries below root,
+// outside of excludeDirs
+func countFiles(fsys FS, root string, excludeDirs []string) (int, error) {
+	total := 0
+	err := walkTree(fsys, root, excludeDirs, func(_, _ string, info fs.FileInfo) error {
+		if !info.IsDir() {
+			total++
+		}
+		return nil
+	})
+	return total, err
+}
+
+// copyTree copies src into dst. visiting holds the directories being
+// copied, to detect directory symlinks looping on an ancestor.
+// tick, when set, is called after each non directory entry of src.
+func copyTree(fsys FS, src, dst string, opts CopyOptions, visiting map[string]bool, tick func()) error {
+	visiting[src] = true
+	defer delete(visiting, src)
+
+	srcInfo, err := fsys.Lstat(src)
+	if err != nil {
+		return fmt.Errorf("stat source %q: %w", src, err)
+	}
+	if !srcInfo.IsDir() {
+		return fmt.Errorf("source %q is not a directory", src)
+	}
+
+	// create destination root with same permissions as src
+	if err := fsys.MkdirAll(dst, srcInfo.Mode().Perm()); err != nil {
+		return fmt.Errorf("create destination %q: %w", dst, err)
+	}
+
+	// Walk the source tree, its root being already created
+	return walkTree(fsys, src, opts.ExcludeDirs, func(path, rel string, info fs.FileInfo) error {
+		if tick != nil && !info.IsDir() {
+			defer tick()
+		}
+
+		targetPath := filepath.Join(dst, rel)
 
 		// Handle symlinks explicitly (recreate the symlink)
 		if info.Mode()&os.ModeSymlink != 0 {
-			linkTarget, err := os.Readlink(path)
+			linkTarget, err := fsys.Readlink(path)
 			if err != nil {
 				return fmt.Errorf("readlink %q: %w", path, err)
 			}
+			if symlinkEscapes(src, path, linkTarget) {
+				switch opts.EscapingSymlinks {
+				case SymlinkStrict:
+					return fmt.Errorf("symlink %q -> %q escapes source %q", path, linkTarget, src)
+				case SymlinkRewrite:
+					linkTarget, err = rewriteSymlink(path, linkTarget, targetPath)
+					if err != nil {
+						return err
+					}
+				}
+			}
+			if opts.Dereference {
+				return copyDereferenced(fsys, path, rel, targetPath, opts, visiting)
+			}
 			// remove existing target if present to allow overwrite
-			_ = os.Remove(targetPath)
-			if err := os.Symlink(linkTarget, targetPath); err != nil {
+			_ = fsys.Remove(targetPath)
+			if err := fsys.Symlink(linkTarget, targetPath); err != nil {
 				return fmt.Errorf("symlink %q -> %q: %w", targetPath, linkTarget, err)
 			}
 			return nil
@@ -74,19 +236,30 @@ func CopyDir(src, dst string) error {
 
 		if info.IsDir() {
 			// create directory with same mode
-			if err := os.MkdirAll(targetPath, info.Mode()); err != nil {
+			if err := fsys.MkdirAll(targetPath, info.Mode().Perm()); err != nil {
 				return fmt.Errorf("mkdir %q: %w", targetPath, err)
 			}
 			return nil
 		}
 
+		if opts.SkipUnchanged {
+			unchanged, err := isUnchanged(fsys, path, info, targetPath, opts.CompareContent)
+			if err != nil {
+				return err
+			}
+			if unchanged {
+				return nil
+			}
+		}
+
 		// Regular file: copy contents and set mode + modtime
-		if err := copyFile(path, targetPath, info.Mode()); err != nil {
+		if err := copyFile(fsys, path, targetPath, info.Mode()); err != nil {
 			return err
 		}
+		opts.Stats.add(info.Size())
 		// preserve modification time
 		modTime := info.ModTime()
-		if err := os.Chtimes(targetPath, modTime, modTime); err != nil {
+		if err := fsys.Chtimes(targetPath, modTime, modTime); err != nil {
 			// non-fatal on some platforms, but return error to be strict
 			return fmt.Errorf("chtimes %q: %w", targetPath, err)
 		}
@@ -94,26 +267,166 @@ func CopyDir(src, dst string) error {
 	})
 }
 
-func copyFile(srcFile, dstFile string, mode os.FileMode) error {
+// maxSymlinkHops bounds symlink chains, like the kernel does (ELOOP)
+const maxSymlinkHops = 40
+
+// followSymlink resolves the symlink at path, following chains of symlinks,
+// and returns the final path and its info.
+func followSymlink(fsys FS, path string) (string, fs.FileInfo, error) {
+	current := path
+	for range maxSymlinkHops {
+		linkTarget, err := fsys.Readlink(current)
+		if err != nil {
+			return "", nil, fmt.Errorf("readlink %q: %w", current, err)
+		}
+		current = resolveSymlink(current, linkTarget)
+		info, err := fsys.Lstat(current)
+		if err != nil {
+			return "", nil, fmt.Errorf("dangling symlink %q -> %q: %w", path, linkTarget, err)
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			return current, info, nil
+		}
+	}
+	return "", nil, fmt.Errorf("circular symlink %q", path)
+}
+
+// copyDereferenced copies what the symlink at path, rel below the source,
+// points to into targetPath
+func copyDereferenced(fsys FS, path, rel, targetPath string, opts CopyOptions, visiting map[string]bool) error {
+	resolved, info, err := followSymlink(fsys, path)
+	if err != nil {
+		return err
+	}
+	// replace a previous copy of the link itself
+	if existing, err := fsys.Lstat(targetPath); err == nil && existing.Mode()&os.ModeSymlink != 0 {
+		_ = fsys.Remove(targetPath)
+	}
+	if info.IsDir() {
+		if visiting[resolved] {
+			return fmt.Errorf("circular symlink %q -> %q", path, resolved)
+		}
+		// The excluded directories are relative to the source, not to the
+		// symlink target
+		if isExcludedDir(".", rel, opts.ExcludeDirs) {
+			return nil
+		}
+		opts.ExcludeDirs = excludedDirsBelow(rel, opts.ExcludeDirs)
+		// the whole directory counts as the single symlink entry for progress
+		return copyTree(fsys, resolved, targetPath, opts, visiting, nil)
+	}
+	if err := copyFile(fsys, resolved, targetPath, info.Mode()); err != nil {
+		return err
+	}
+	opts.Stats.add(info.Size())
+	modTime := info.ModTime()
+	if err := fsys.Chtimes(targetPath, modTime, modTime); err != nil {
+		return fmt.Errorf("chtimes %q: %w", targetPath, err)
+	}
+	return nil
+}
+
+// isUnchanged reports whether targetPath is a regular file with the same size,
+// modification time and permission bits as the source file described by info
+// (and the same content when compareContent is set).
+func isUnchanged(fsys FS, path string, info fs.FileInfo, targetPath string, compareContent bool) (bool, error) {
+	targetInfo, err := fsys.Lstat(targetPath)
+	if err != nil {
+		return false, nil
+	}
+	if !targetInfo.Mode().IsRegular() ||
+		targetInfo.Size() != info.Size() ||
+		!targetInfo.ModTime().Equal(info.ModTime()) ||
+		targetInfo.Mode().Perm() != info.Mode().Perm() {
+		return false, nil
+	}
+	if !compareContent {
+		return true, nil
+	}
+	srcHash, err := hashFile(fsys, path)
+	if err != nil {
+		return false, err
+	}
+	targetHash, err := hashFile(fsys, targetPath)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(srcHash, targetHash), nil
+}
+
+// hashFile returns the SHA-256 of a file content
+func hashFile(fsys FS, path string) ([]byte, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open %q: %w", path, err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, fmt.Errorf("hash %q: %w", path, err)
+	}
+	return h.Sum(nil), nil
+}
+
+// symlinkEscapes reports whether the symlink at path, pointing to linkTarget,
+// resolves (lexically) outside of the src tree.
+func symlinkEscapes(src, path, linkTarget string) bool {
+	resolved := resolveSymlink(path, linkTarget)
+	rel, err := filepath.Rel(src, resolved)
+	if err != nil {
+		return true
+	}
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// resolveSymlink returns the cleaned path the symlink at path points to
+func resolveSymlink(path, linkTarget string) string {
+	if filepath.IsAbs(linkTarget) {
+		return filepath.Clean(linkTarget)
+	}
+	return filepath.Join(filepath.Dir(path), linkTarget)
+}
+
+// rewriteSymlink returns a relative link target which, placed at targetPath,
+// points to the same file as the symlink at path.
+func rewriteSymlink(path, linkTarget, targetPath string) (string, error) {
+	resolved, err := filepath.Abs(resolveSymlink(path, linkTarget))
+	if err != nil {
+		return "", fmt.Errorf("resolve symlink %q: %w", path, err)
+	}
+	targetDir, err := filepath.Abs(filepath.Dir(targetPath))
+	if err != nil {
+		return "", fmt.Errorf("resolve symlink destination %q: %w", targetPath, err)
+	}
+	rel, err := filepath.Rel(targetDir, resolved)
+	if err != nil {
+		return "", fmt.Errorf("rewrite symlink %q: %w", path, err)
+	}
+	return rel, nil
+}
+
+func copyFile(fsys FS, srcFile, dstFile string, mode os.FileMode) error {
 	// ensure parent dir exists
-	if err := os.MkdirAll(filepath.Dir(dstFile), 0o755); err != nil {
+	if err := fsys.MkdirAll(filepath.Dir(dstFile), 0o755); err != nil {
 		return fmt.Errorf("mkdir parent for %q: %w", dstFile, err)
 	}
 
-	in, err := os.Open(srcFile)
+	in, err := fsys.Open(srcFile)
 	if err != nil {
 		return fmt.Errorf("open source file %q: %w", srcFile, err)
 	}
 	defer in.Close()
 
 	// create destination with the same mode
-	out, err := os.OpenFile(dstFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	out, err := fsys.Create(dstFile, mode)
 	if err != nil {
 		return fmt.Errorf("create destination file %q: %w", dstFile, err)
 	}
 	defer func() {
 		// ensure file is closed and synced
-		_ = out.Sync()
+		if syncer, ok := out.(interface{ Sync() error }); ok {
+			_ = syncer.Sync()
+		}
 		_ = out.Close()
 	}()
 
@@ -122,15 +435,10 @@ func copyFile(srcFile, dstFile string, mode os.FileMode) error {
 	}
 
 	// ensure permission bits are set (in case umask changed creation)
-	if err := os.Chmod(dstFile, mode); err != nil {
+	if err := fsys.Chmod(dstFile, mode); err != nil {
 		return fmt.Errorf("chmod %q: %w", dstFile, err)
 	}
 
-	// preserve access/mod times using a best-effort approach
-	now := time.Now()
-	if err := os.Chtimes(dstFile, now, now); err != nil {
-		// ignore; not all platforms support Chtimes on all filesystems
-	}
-
+	// The caller preserves the source modification time
 	return nil
 }
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newEscapingTree creates root/src with a symlink escaping it towards root/secret
+func newEscapingTree(t *testing.T) (root, src string) {
+	t.Helper()
+	root = t.TempDir()
+	src = filepath.Join(root, "src")
+	if err := os.MkdirAll(filepath.Join(src, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "secret"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join("..", "..", "secret"), filepath.Join(src, "docs", "link")); err != nil {
+		t.Fatal(err)
+	}
+	return root, src
+}
+
+func TestCopyDirEscapingSymlinks(t *testing.T) {
+	root, src := newEscapingTree(t)
+
+	t.Run("keep by default", func(t *testing.T) {
+		dst := filepath.Join(root, "keep")
+		if err := CopyDir(src, dst); err != nil {
+			t.Fatalf("CopyDir() error = %v", err)
+		}
+		got, err := os.Readlink(filepath.Join(dst, "docs", "link"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := filepath.Join("..", "..", "secret"); got != want {
+			t.Errorf("link target = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		dst := filepath.Join(root, "strict")
+		err := CopyDirWithOptions(src, dst, CopyOptions{EscapingSymlinks: SymlinkStrict})
+		if err == nil || !strings.Contains(err.Error(), "escapes source") {
+			t.Fatalf("CopyDirWithOptions() error = %v, want escaping symlink error", err)
+		}
+	})
+
+	t.Run("rewrite", func(t *testing.T) {
+		dst := filepath.Join(root, "out", "rewrite")
+		if err := CopyDirWithOptions(src, dst, CopyOptions{EscapingSymlinks: SymlinkRewrite}); err != nil {
+			t.Fatalf("CopyDirWithOptions() error = %v", err)
+		}
+		content, err := os.ReadFile(filepath.Join(dst, "docs", "link"))
+		if err != nil {
+			t.Fatalf("rewritten link does not resolve: %v", err)
+		}
+		if string(content) != "secret" {
+			t.Errorf("rewritten link content = %q, want %q", content, "secret")
+		}
+	})
+}
+
+func TestCopyDirInternalSymlinkIsNotEscaping(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	if err := os.MkdirAll(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "page.md"), []byte("page"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("page.md", filepath.Join(src, "alias.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(root, "dst")
+	if err := CopyDirWithOptions(src, dst, CopyOptions{EscapingSymlinks: SymlinkStrict}); err != nil {
+		t.Fatalf("CopyDirWithOptions() error = %v", err)
+	}
+	if got, _ := os.Readlink(filepath.Join(dst, "alias.md")); got != "page.md" {
+		t.Errorf("link target = %q, want %q", got, "page.md")
+	}
+}
+
+func TestCopyDirDereference(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	shared := filepath.Join(root, "shared")
+	for _, dir := range []string{src, filepath.Join(shared, "nested")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(src, "page.md"), []byte("page"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(shared, "nested", "snippet.md"), []byte("snippet"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("page.md", filepath.Join(src, "file-link.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(shared, filepath.Join(src, "dir-link")); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(root, "dst")
+	if err := CopyDirWithOptions(src, dst, CopyOptions{Dereference: true}); err != nil {
+		t.Fatalf("CopyDirWithOptions() error = %v", err)
+	}
+
+	fileInfo, err := os.Lstat(filepath.Join(dst, "file-link.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fileInfo.Mode().IsRegular() {
+		t.Errorf("file-link.md mode = %v, want a regular file", fileInfo.Mode())
+	}
+	if content, _ := os.ReadFile(filepath.Join(dst, "file-link.md")); string(content) != "page" {
+		t.Errorf("file-link.md content = %q, want %q", content, "page")
+	}
+
+	dirInfo, err := os.Lstat(filepath.Join(dst, "dir-link"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dirInfo.IsDir() {
+		t.Errorf("dir-link mode = %v, want a real directory", dirInfo.Mode())
+	}
+	if content, _ := os.ReadFile(filepath.Join(dst, "dir-link", "nested", "snippet.md")); string(content) != "snippet" {
+		t.Errorf("dir-link/nested/snippet.md content = %q, want %q", content, "snippet")
+	}
+}
+
+func TestCopyDirDereferenceExcludeDirs(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"src/page.md":                  "page",
+		"shared/nested/snippet.md":     "snippet",
+		"shared/examples/generated.md": "generated",
+	})
+	src := filepath.Join(root, "src")
+	for _, link := range []string{"dir-link", "other-link"} {
+		if err := os.Symlink(filepath.Join(root, "shared"), filepath.Join(src, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Excluded directories are relative to the source, also below a
+	// dereferenced symlink: "nested" is not dir-link/nested
+	dst := filepath.Join(root, "dst")
+	opts := CopyOptions{Dereference: true, ExcludeDirs: []string{"dir-link/examples", "other-link", "nested"}}
+	if err := CopyDirWithOptions(src, dst, opts); err != nil {
+		t.Fatalf("CopyDirWithOptions() error = %v", err)
+	}
+	for path, wantCopied := range map[string]bool{
+		"page.md":                    true,
+		"dir-link/nested/snippet.md": true,
+		"dir-link/examples":          false,
+		"other-link":                 false,
+	} {
+		_, err := os.Lstat(filepath.Join(dst, filepath.FromSlash(path)))
+		if copied := err == nil; copied != wantCopied {
+			t.Errorf("%s copied = %v, want %v", path, copied, wantCopied)
+		}
+	}
+}
+
+func TestCopyDirDereferenceErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		want   string
+	}{
+		{name: "dangling", target: "missing.md", want: "dangling symlink"},
+		{name: "circular directory", target: ".", want: "circular symlink"},
+		{name: "self loop", target: "link", want: "circular symlink"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			src := filepath.Join(root, "src")
+			if err := os.MkdirAll(src, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Symlink(tt.target, filepath.Join(src, "link")); err != nil {
+				t.Fatal(err)
+			}
+			err := CopyDirWithOptions(src, filepath.Join(root, "dst"), CopyOptions{Dereference: true})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("CopyDirWithOptions() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCopyDirPreservesModeAndModTime(t *testing.T) {
+	src := t.TempDir()
+	dst := filepath.Join(t.TempDir(), "dst")
+	script := filepath.Join(src, "scripts", "install.sh")
+	if err := os.MkdirAll(filepath.Dir(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(script, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CopyDir(src, dst); err != nil {
+		t.Fatalf("CopyDir() error = %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dst, "scripts", "install.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("mode = %v, want 0755", info.Mode().Perm())
+	}
+	if !info.ModTime().Equal(modTime) {
+		t.Errorf("modtime = %v, want %v", info.ModTime(), modTime)
+	}
+}
+
+// shuffledFS lists directories in reverse order and records the files it
+// creates, to check the copy does not depend on the listing order
+type shuffledFS struct {
+	osFS
+	reverse bool
+	created []string
+}
+
+func (s *shuffledFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := s.osFS.ReadDir(name)
+	if s.reverse {
+		slices.Reverse(entries)
+	}
+	return entries, err
+}
+
+func (s *shuffledFS) Create(name string, mode fs.FileMode) (io.WriteCloser, error) {
+	s.created = append(s.created, filepath.Base(name))
+	return s.osFS.Create(name, mode)
+}
+
+// snapshotTree describes each entry below root by its mode and the hash of
+// its content, or its target for symlinks
+func snapshotTree(t *testing.T, root string) map[string]string {
+	t.Helper()
+	tree := map[string]string{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		desc := info.Mode().String()
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			desc += " -> " + target
+		case info.Mode().IsRegular():
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			desc += fmt.Sprintf(" %x", sha256.Sum256(content))
+		}
+		tree[rel] = desc
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+func TestCopyDirDeterministic(t *testing.T) {
+	src := t.TempDir()
+	files := map[string]os.FileMode{"b.md": 0644, "a.md": 0600, "z/run.sh": 0755, "z/c.md": 0644, "m/n/o.md": 0644}
+	contents := map[string]string{}
+	for name := range files {
+		contents[name] = name
+	}
+	writeFiles(t, src, contents)
+	for name, mode := range files {
+		if err := os.Chmod(filepath.Join(src, name), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("a.md", filepath.Join(src, "link.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	var created [][]string
+	var progress [][]int
+	var trees []map[string]string
+	for _, reverse := range []bool{false, true} {
+		fsys := &shuffledFS{reverse: reverse}
+		var calls []int
+		dst := filepath.Join(t.TempDir(), "dst")
+		opts := CopyOptions{FS: fsys, Progress: func(copied, total int) { calls = append(calls, copied, total) }}
+		if err := CopyDirWithOptions(src, dst, opts); err != nil {
+			t.Fatalf("CopyDirWithOptions() error = %v", err)
+		}
+		created = append(created, fsys.created)
+		progress = append(progress, calls)
+		trees = append(trees, snapshotTree(t, dst))
+	}
+
+	if !slices.Equal(created[0], created[1]) {
+		t.Errorf("files created in order %v, then %v", created[0], created[1])
+	}
+	if !slices.Equal(progress[0], progress[1]) {
+		t.Errorf("progress reported %v, then %v", progress[0], progress[1])
+	}
+	if !maps.Equal(trees[0], trees[1]) {
+		t.Errorf("copied trees differ:\n%v\n%v", trees[0], trees[1])
+	}
+	if len(trees[0]) != len(files)+1+4 {
+		t.Errorf("copied tree = %v, want the %d files, the symlink and 4 directories", trees[0], len(files))
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"go.yaml.in/yaml/v3"
+)
+
+// Supported formats of the versions data files
+const (
+	formatTOML = "toml"
+	formatYAML = "yaml"
+)
+
+// validateDataFormat checks the value given to --data-format
+func validateDataFormat(format string) error {
+	switch format {
+	case "", formatTOML, formatYAML:
+		return nil
+	}
+	return invalidf("unknown data format %q: must be %s or %s", format, formatTOML, formatYAML)
+}
+
+// dataFilePath returns the versions data file of project. Unless the
+// format is forced, an existing YAML file is preferred over the TOML
+// default, e.g. data/eso_versions.yaml over data/eso_versions.toml.
+func (s Settings) dataFilePath(root, project string) string {
+	base := filepath.Join(root, "data", project+"_versions")
+	switch s.DataFormat {
+	case formatTOML:
+		return base + ".toml"
+	case formatYAML:
+		if _, err := os.Stat(base + ".yml"); err == nil {
+			return base + ".yml"
+		}
+		return base + ".yaml"
+	}
+	for _, ext := range []string{".yaml", ".yml"} {
+		if _, err := os.Stat(base + ext); err == nil {
+			return base + ext
+		}
+	}
+	return base + ".toml"
+}
+
+// versionsFormat returns the format filename is read and written in, from
+// its extension. dataFilePath gives the extension of a forced format.
+func versionsFormat(filename string) string {
+	switch filepath.Ext(filename) {
+	case ".yaml", ".yml":
+		return formatYAML
+	}
+	return formatTOML
+}
+
+// decodeVersions parses content in the given format
+func decodeVersions(format string, content []byte, data *VersionsData) error {
+	if format == formatYAML {
+		return yaml.Unmarshal(content, data)
+	}
+	_, err := toml.Decode(string(content), data)
+	return err
+}
+
+// encodeVersions serialises data in the given format
+func (s Settings) encodeVersions(format string, data *VersionsData) ([]byte, error) {
+	if format == formatYAML {
+		return yaml.Marshal(data)
+	}
+	if s.CompactTOML {
+		return encodeCompactTOML(data), nil
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeCompactTOML serialises data as TOML with one inline table per version
+func encodeCompactTOML(data *VersionsData) []byte {
+	var b strings.Builder
+	if data.ProjectLongName != "" {
+		fmt.Fprintf(&b, "project_long_name = %s\n", tomlString(data.ProjectLongName))
+	}
+	if data.DefaultEOLMonths != 0 {
+		fmt.Fprintf(&b, "default_eol_months = %d\n", data.DefaultEOLMonths)
+	}
+	if data.LatestLabel != "" {
+		fmt.Fprintf(&b, "latest_label = %s\n", tomlString(data.LatestLabel))
+	}
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("versions = [\n")
+	for _, v := range data.Versions {
+		k8sVersions := make([]string, len(v.TestedK8sVersions))
+		for i, k8s := range v.TestedK8sVersions {
+			k8sVersions[i] = tomlString(k8s)
+		}
+		fmt.Fprintf(&b, "  { tag = %s, latest = %t, release_date = %s, tested_k8s_versions = [%s], end_of_life = %s",
+			tomlString(v.Tag), v.Latest, tomlString(v.ReleaseDate), strings.Join(k8sVersions, ", "), tomlString(v.EndOfLife))
+		if v.Version != "" {
+			fmt.Fprintf(&b, ", version = %s", tomlString(v.Version))
+		}
+		b.WriteString(" },\n")
+	}
+	b.WriteString("]\n")
+	return []byte(b.String())
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVersionsYAMLRoundTrip(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "eso_versions.yaml")
+	want := &VersionsData{Versions: []Version{
+		{Tag: "v0.15.0", Latest: true, ReleaseDate: "2025-02-01", TestedK8sVersions: []string{"v1.32", "v1.33"}},
+		{Tag: "v0.14.0", ReleaseDate: "2025-01-01", TestedK8sVersions: []string{"v1.32"}, EndOfLife: "2025-06-01"},
+	}}
+
+	if err := (Settings{}).writeVersions(filename, want); err != nil {
+		t.Fatalf("writeVersions() error = %v", err)
+	}
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "tested_k8s_versions:") {
+		t.Errorf("data file is not YAML:\n%s", content)
+	}
+
+	got, err := readVersions(filename)
+	if err != nil {
+		t.Fatalf("readVersions() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readVersions() = %+v, want %+v", got, want)
+	}
+}
+
+func TestVersionsFormatOverride(t *testing.T) {
+	settings := Settings{DataFormat: formatYAML}
+
+	dataFile := settings.dataFilePath("root", "eso")
+	if want := filepath.Join("root", "data", "eso_versions.yaml"); dataFile != want {
+		t.Errorf("dataFilePath() = %q, want %q", dataFile, want)
+	}
+	if got := versionsFormat(dataFile); got != formatYAML {
+		t.Errorf("versionsFormat() = %q, want %q", got, formatYAML)
+	}
+}
+
+func TestDataFilePathDetectsYAML(t *testing.T) {
+	root := t.TempDir()
+	if got, want := (Settings{}).dataFilePath(root, "eso"), filepath.Join(root, "data", "eso_versions.toml"); got != want {
+		t.Errorf("dataFilePath() = %q, want %q", got, want)
+	}
+
+	yamlFile := filepath.Join(root, "data", "eso_versions.yml")
+	if err := os.MkdirAll(filepath.Dir(yamlFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(yamlFile, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := (Settings{}).dataFilePath(root, "eso"); got != yamlFile {
+		t.Errorf("dataFilePath() = %q, want %q", got, yamlFile)
+	}
+}
+
+func TestValidateDataFormat(t *testing.T) {
+	for _, format := range []string{"", "toml", "yaml"} {
+		if err := validateDataFormat(format); err != nil {
+			t.Errorf("validateDataFormat(%q) error = %v", format, err)
+		}
+	}
+	if err := validateDataFormat("json"); err == nil {
+		t.Error("validateDataFormat(json) succeeded, want an error")
+	}
+}
+
+func TestCompactTOML(t *testing.T) {
+	settings := Settings{CompactTOML: true}
+	filename := filepath.Join(t.TempDir(), "eso_versions.toml")
+	want := &VersionsData{ProjectLongName: "External Secrets \"ESO\"", Versions: []Version{
+		{Tag: "v0.15.3", Latest: true, ReleaseDate: "2025-02-01", TestedK8sVersions: []string{"v1.32", "v1.33"}, Version: "v0.15"},
+		{Tag: "v0.14.0", ReleaseDate: "2025-01-01", TestedK8sVersions: []string{"v1.32"}, EndOfLife: "2025-06-01"},
+	}}
+
+	if err := settings.writeVersions(filename, want); err != nil {
+		t.Fatalf("writeVersions() error = %v", err)
+	}
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := `project_long_name = "External Secrets \"ESO\""
+
+versions = [
+  { tag = "v0.15.3", latest = true, release_date = "2025-02-01", tested_k8s_versions = ["v1.32", "v1.33"], end_of_life = "", version = "v0.15" },
+  { tag = "v0.14.0", latest = false, release_date = "2025-01-01", tested_k8s_versions = ["v1.32"], end_of_life = "2025-06-01" },
+]
+`
+	if string(content) != golden {
+		t.Errorf("compact data file =\n%s\nwant\n%s", content, golden)
+	}
+
+	got, err := readVersions(filename)
+	if err != nil {
+		t.Fatalf("readVersions() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readVersions() = %+v, want %+v", got, want)
+	}
+}
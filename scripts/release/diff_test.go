@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	before := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	after := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\n"
+	want := `--- a/file
++++ b/file
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -9,3 +9,4 @@
+ i
+ j
+ k
++l
+`
+	if got := unifiedDiff("file", before, after); got != want {
+		t.Errorf("unifiedDiff() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestUnifiedDiffNewFile(t *testing.T) {
+	want := "--- /dev/null\n+++ b/file\n@@ -0,0 +1,2 @@\n+a\n+b\n"
+	if got := unifiedDiff("file", "", "a\nb\n"); got != want {
+		t.Errorf("unifiedDiff() = %q, want %q", got, want)
+	}
+	if got := unifiedDiff("file", "a\n", "a\n"); got != "" {
+		t.Errorf("unifiedDiff() of equal contents = %q, want none", got)
+	}
+}
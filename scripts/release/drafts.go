@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"go.yaml.in/yaml/v3"
+)
+
+// splitFrontMatter returns the delimiter, +++ for TOML or --- for YAML, and
+// the front matter of a page, false when it has none
+func splitFrontMatter(content string) (delimiter string, frontMatter string, ok bool) {
+	lines := strings.SplitAfter(content, "\n")
+	delimiter = strings.TrimSpace(lines[0])
+	if delimiter != "+++" && delimiter != "---" {
+		return "", "", false
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == delimiter {
+			return delimiter, strings.Join(lines[1:i], ""), true
+		}
+	}
+	return "", "", false
+}
+
+// isDraft reports whether the front matter of a page, TOML (+++) or YAML
+// (---), sets draft = true. Content without front matter is no draft.
+func isDraft(content string) (bool, error) {
+	delimiter, frontMatter, ok := splitFrontMatter(content)
+	if !ok {
+		return false, nil
+	}
+
+	var page struct {
+		Draft bool `toml:"draft" yaml:"draft"`
+	}
+	if delimiter == "+++" {
+		_, err := toml.Decode(frontMatter, &page)
+		return page.Draft, err
+	}
+	err := yaml.Unmarshal([]byte(frontMatter), &page)
+	return page.Draft, err
+}
+
+// stripDrafts removes the draft markdown pages below dir, then the
+// directories they leave empty, except dir itself. It returns the removed
+// pages.
+func stripDrafts(dir string) ([]string, error) {
+	var removed []string
+	var dirs []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if d.IsDir() {
+			dirs = append(dirs, path)
+			return nil
+		}
+		if !d.Type().IsRegular() || filepath.Ext(path) != ".md" {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		draft, err := isDraft(string(content))
+		if err != nil {
+			return fmt.Errorf("cannot parse the front matter of %s: %w", path, err)
+		}
+		if !draft {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed = append(removed, path)
+		return nil
+	})
+	if err != nil {
+		return removed, err
+	}
+
+	// Directories that were already empty are kept. Walking them deepest
+	// first prunes the parents emptied by their children too.
+	emptied := map[string]bool{}
+	for _, page := range removed {
+		emptied[filepath.Dir(page)] = true
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if dirs[i] == dir || !emptied[dirs[i]] {
+			continue
+		}
+		empty, err := dirIsEmpty(dirs[i])
+		if err != nil {
+			return removed, err
+		}
+		if empty {
+			if err := os.Remove(dirs[i]); err != nil {
+				return removed, err
+			}
+			emptied[filepath.Dir(dirs[i])] = true
+		}
+	}
+	return removed, nil
+}
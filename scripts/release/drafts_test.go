@@ -0,0 +1,95 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestIsDraft(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+		wantErr bool
+	}{
+		{name: "toml draft", content: "+++\ntitle = \"a\"\ndraft = true\n+++\nbody\n", want: true},
+		{name: "toml not draft", content: "+++\ndraft = false\n+++\n", want: false},
+		{name: "yaml draft", content: "---\ntitle: a\ndraft: true\n---\nbody\n", want: true},
+		{name: "yaml without draft", content: "---\ntitle: a\n---\n", want: false},
+		{name: "crlf", content: "+++\r\ndraft = true\r\n+++\r\n", want: true},
+		{name: "no front matter", content: "draft = true\n", want: false},
+		{name: "unterminated", content: "+++\ndraft = true\n", want: false},
+		{name: "invalid toml", content: "+++\ndraft = \n+++\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := isDraft(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("isDraft() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("isDraft() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripDrafts(t *testing.T) {
+	dir := t.TempDir()
+	pages := map[string]string{
+		"_index.md":             "+++\ntitle = \"v0.15\"\n+++\n",
+		"guide/page.md":         "---\ntitle: page\n---\n",
+		"guide/wip.md":          "---\ndraft: true\n---\n",
+		"drafts/only/new.md":    "+++\ndraft = true\n+++\n",
+		"drafts/notes.txt.keep": "draft = true\n",
+	}
+	writeFiles(t, dir, pages)
+	if err := os.MkdirAll(filepath.Join(dir, "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := stripDrafts(dir)
+	if err != nil {
+		t.Fatalf("stripDrafts() error = %v", err)
+	}
+	want := []string{filepath.Join(dir, "drafts", "only", "new.md"), filepath.Join(dir, "guide", "wip.md")}
+	if !slices.Equal(removed, want) {
+		t.Errorf("stripDrafts() = %v, want %v", removed, want)
+	}
+	for _, kept := range []string{"_index.md", "guide/page.md", "drafts/notes.txt.keep", "empty"} {
+		if _, err := os.Stat(filepath.Join(dir, kept)); err != nil {
+			t.Errorf("%s was removed: %v", kept, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "drafts", "only")); !os.IsNotExist(err) {
+		t.Errorf("drafts/only emptied by the drafts was not pruned, stat error = %v", err)
+	}
+}
+
+func TestAddReleaseStripDrafts(t *testing.T) {
+	root := newTestRepo(t)
+	unreleased := filepath.Join(root, "content", "en", "eso-docs", "unreleased")
+	if err := os.MkdirAll(filepath.Join(unreleased, "wip"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(unreleased, "wip", "new.md"), []byte("+++\ndraft = true\n+++\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33", StripDrafts: true})
+	if err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	newVersionDir := filepath.Join(root, "content", "en", "eso-docs", "v0.15")
+	if _, err := os.Stat(filepath.Join(newVersionDir, "wip")); !os.IsNotExist(err) {
+		t.Errorf("the draft was copied, stat error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(newVersionDir, "guide", "page.md")); err != nil {
+		t.Errorf("guide/page.md was not copied: %v", err)
+	}
+	if slices.Contains(changes.FilesCreated, "content/en/eso-docs/v0.15/wip/new.md") {
+		t.Errorf("files created = %v, want the draft left out", changes.FilesCreated)
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/mod/semver"
+)
+
+// Support statuses of a version
+const (
+	StatusSupported    = "supported"
+	StatusExpiringSoon = "expiring-soon"
+	StatusExpired      = "expired"
+)
+
+// defaultExpiringDays is how close to its end of life a version is
+// reported as expiring soon by default
+const defaultExpiringDays = 30
+
+// EOLEntry is the end of life schedule of a version
+type EOLEntry struct {
+	Tag         string `json:"tag"`
+	ReleaseDate string `json:"release_date"`
+	// EndOfLife is stored in the data file or computed from its
+	// default_eol_months, empty when unknown
+	EndOfLife string `json:"end_of_life"`
+	Status    string `json:"status"`
+}
+
+func handleListEOL(settings Settings, project string, expiringDays int, asJSON bool) {
+	if project == "" {
+		fmt.Print("Missing project\n")
+		printReleaseUsage()
+		os.Exit(1)
+	}
+	if err := validateProject(project); err != nil {
+		exitWithError(err)
+	}
+
+	versions, err := readVersions(settings.dataFilePath("", project))
+	if err != nil {
+		exitWithError(err)
+	}
+
+	entries, err := eolSchedule(versions, time.Now(), expiringDays)
+	if err != nil {
+		exitWithError(err)
+	}
+	if err := printEOLSchedule(os.Stdout, entries, asJSON); err != nil {
+		exitWithError(err)
+	}
+}
+
+// eolSchedule classifies versions at now: expired after their end of life,
+// expiring soon within expiringDays of it, supported otherwise, including
+// when the end of life is unknown.
+func eolSchedule(versions *VersionsData, now time.Time, expiringDays int) ([]EOLEntry, error) {
+	today := now.Format("2006-01-02")
+	threshold := now.AddDate(0, 0, expiringDays).Format("2006-01-02")
+
+	entries := []EOLEntry{}
+	for _, v := range versions.Versions {
+		eol := v.EndOfLife
+		if eol == "" && v.ReleaseDate != "" {
+			computed, err := versions.endOfLife(v.ReleaseDate)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", v.Tag, err)
+			}
+			eol = computed
+		}
+		if eol != "" {
+			if _, err := time.Parse("2006-01-02", eol); err != nil {
+				return nil, invalidf("%s: invalid end of life %q, expected YYYY-MM-DD", v.Tag, eol)
+			}
+		}
+
+		// Dates are YYYY-MM-DD so they compare as strings
+		status := StatusSupported
+		switch {
+		case eol == "":
+		case eol < today:
+			status = StatusExpired
+		case eol <= threshold:
+			status = StatusExpiringSoon
+		}
+		entries = append(entries, EOLEntry{Tag: v.Tag, ReleaseDate: v.ReleaseDate, EndOfLife: eol, Status: status})
+	}
+	return entries, nil
+}
+
+// printEOLSchedule outputs entries, one per line, or as a JSON array
+func printEOLSchedule(w io.Writer, entries []EOLEntry, asJSON bool) error {
+	if asJSON {
+		out, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", out)
+		return err
+	}
+	for _, e := range entries {
+		eol := e.EndOfLife
+		if eol == "" {
+			eol = "-"
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Tag, e.ReleaseDate, eol, e.Status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ErrNoSupported is returned when every version reached its end of life
+var ErrNoSupported = errors.New("no supported version")
+
+func handleOldestSupported(settings Settings, project string, asJSON bool) {
+	if project == "" {
+		fmt.Print("Missing project\n")
+		printReleaseUsage()
+		os.Exit(1)
+	}
+	if err := validateProject(project); err != nil {
+		exitWithError(err)
+	}
+
+	dataFile := settings.dataFilePath("", project)
+	versions, err := readVersions(dataFile)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	oldest, skipped, err := oldestSupported(versions, time.Now())
+	for _, tag := range skipped {
+		logWarning("skipping %s, its tag or end of life is invalid", tag)
+	}
+	if err != nil {
+		exitWithError(fmt.Errorf("%w in %s", err, dataFile))
+	}
+	if err := printOldestSupported(os.Stdout, oldest, asJSON); err != nil {
+		exitWithError(err)
+	}
+}
+
+// oldestSupported returns the version with the lowest semver which did not
+// reach its end of life at now, stored or computed from default_eol_months.
+// Versions without an end of life are supported. Of equal versions (e.g.
+// v0.15 and v0.15.0) the first one of the data file wins.
+// Versions with an invalid tag or end of life are skipped, and their tags
+// returned separately.
+func oldestSupported(versions *VersionsData, now time.Time) (oldest EOLEntry, skipped []string, err error) {
+	today := now.Format("2006-01-02")
+	found := false
+	for _, v := range versions.Versions {
+		if !semver.IsValid(normalizeVersion(v.Tag)) {
+			skipped = append(skipped, v.Tag)
+			continue
+		}
+		eol := v.EndOfLife
+		if eol == "" && v.ReleaseDate != "" {
+			if eol, err = versions.endOfLife(v.ReleaseDate); err != nil {
+				skipped = append(skipped, v.Tag)
+				continue
+			}
+		}
+		if eol != "" {
+			if _, err := time.Parse("2006-01-02", eol); err != nil {
+				skipped = append(skipped, v.Tag)
+				continue
+			}
+		}
+		// Dates are YYYY-MM-DD so they compare as strings
+		if eol != "" && eol < today {
+			continue
+		}
+		if !found || compareVersions(v.Tag, oldest.Tag) < 0 {
+			oldest = EOLEntry{Tag: v.Tag, ReleaseDate: v.ReleaseDate, EndOfLife: eol, Status: StatusSupported}
+			found = true
+		}
+	}
+	if !found {
+		return EOLEntry{}, skipped, ErrNoSupported
+	}
+	return oldest, skipped, nil
+}
+
+// printOldestSupported outputs the tag of the oldest supported version,
+// or its EOL entry as JSON
+func printOldestSupported(w io.Writer, oldest EOLEntry, asJSON bool) error {
+	if asJSON {
+		out, err := json.MarshalIndent(oldest, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", out)
+		return err
+	}
+	_, err := fmt.Fprintln(w, oldest.Tag)
+	return err
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEOLSchedule(t *testing.T) {
+	versions := &VersionsData{
+		DefaultEOLMonths: 6,
+		Versions: []Version{
+			{Tag: "v0.16.0", ReleaseDate: "2025-05-01", Latest: true},
+			{Tag: "v0.15.0", ReleaseDate: "2025-01-01", EndOfLife: "2025-06-20"},
+			{Tag: "v0.14.0", ReleaseDate: "2024-10-01", EndOfLife: "2025-05-31"},
+			{Tag: "v0.13.0", ReleaseDate: ""},
+		},
+	}
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	entries, err := eolSchedule(versions, now, 30)
+	if err != nil {
+		t.Fatalf("eolSchedule() error = %v", err)
+	}
+	want := []EOLEntry{
+		{Tag: "v0.16.0", ReleaseDate: "2025-05-01", EndOfLife: "2025-11-01", Status: StatusSupported},
+		{Tag: "v0.15.0", ReleaseDate: "2025-01-01", EndOfLife: "2025-06-20", Status: StatusExpiringSoon},
+		{Tag: "v0.14.0", ReleaseDate: "2024-10-01", EndOfLife: "2025-05-31", Status: StatusExpired},
+		{Tag: "v0.13.0", Status: StatusSupported},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("eolSchedule() = %+v, want %+v", entries, want)
+	}
+}
+
+func TestEOLScheduleInvalidDate(t *testing.T) {
+	versions := &VersionsData{Versions: []Version{{Tag: "v0.15.0", EndOfLife: "soon"}}}
+	if _, err := eolSchedule(versions, time.Now(), 30); err == nil {
+		t.Error("eolSchedule() succeeded, want an invalid end of life error")
+	}
+}
+
+func TestPrintEOLSchedule(t *testing.T) {
+	entries := []EOLEntry{
+		{Tag: "v0.15.0", ReleaseDate: "2025-01-01", EndOfLife: "2025-06-20", Status: StatusExpiringSoon},
+		{Tag: "v0.13.0", Status: StatusSupported},
+	}
+
+	var human strings.Builder
+	if err := printEOLSchedule(&human, entries, false); err != nil {
+		t.Fatal(err)
+	}
+	if want := "v0.15.0\t2025-01-01\t2025-06-20\texpiring-soon\nv0.13.0\t\t-\tsupported\n"; human.String() != want {
+		t.Errorf("printEOLSchedule() = %q, want %q", human.String(), want)
+	}
+
+	var out strings.Builder
+	if err := printEOLSchedule(&out, entries, true); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []EOLEntry
+	if err := json.Unmarshal([]byte(out.String()), &decoded); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out.String())
+	}
+	if !reflect.DeepEqual(decoded, entries) {
+		t.Errorf("JSON output = %+v, want %+v", decoded, entries)
+	}
+}
+
+func TestOldestSupported(t *testing.T) {
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		versions    *VersionsData
+		want        string
+		wantSkipped []string
+		wantErr     error
+	}{
+		{
+			name: "expired versions are ignored",
+			versions: &VersionsData{DefaultEOLMonths: 6, Versions: []Version{
+				{Tag: "v0.16.0", ReleaseDate: "2025-05-01", Latest: true},
+				{Tag: "v0.15.0", ReleaseDate: "2025-01-01", EndOfLife: "2025-06-20"},
+				{Tag: "v0.14.0", ReleaseDate: "2024-10-01", EndOfLife: "2025-05-31"},
+				{Tag: "v0.13.0", ReleaseDate: "2024-06-01"},
+			}},
+			want: "v0.15.0",
+		},
+		{
+			name: "end of life today is still supported",
+			versions: &VersionsData{Versions: []Version{
+				{Tag: "v0.15.0", EndOfLife: "2025-07-01"},
+				{Tag: "v0.14.0", EndOfLife: "2025-06-01"},
+			}},
+			want: "v0.14.0",
+		},
+		{
+			name: "unknown end of life is supported",
+			versions: &VersionsData{Versions: []Version{
+				{Tag: "v0.15.0", EndOfLife: "2025-07-01"},
+				{Tag: "v0.9.0"},
+			}},
+			want: "v0.9.0",
+		},
+		{
+			name: "ties keep the first version",
+			versions: &VersionsData{Versions: []Version{
+				{Tag: "v0.16.0"},
+				{Tag: "v0.15", EndOfLife: "2025-07-01"},
+				{Tag: "v0.15.0", EndOfLife: "2025-08-01"},
+			}},
+			want: "v0.15",
+		},
+		{
+			name: "invalid tags and dates are skipped",
+			versions: &VersionsData{Versions: []Version{
+				{Tag: "v0.16.0"},
+				{Tag: "v0.15.0", EndOfLife: "soon"},
+				{Tag: "main"},
+			}},
+			want:        "v0.16.0",
+			wantSkipped: []string{"v0.15.0", "main"},
+		},
+		{
+			name: "all expired",
+			versions: &VersionsData{Versions: []Version{
+				{Tag: "v0.14.0", EndOfLife: "2025-05-31"},
+			}},
+			wantErr: ErrNoSupported,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, skipped, err := oldestSupported(tt.versions, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("oldestSupported() error = %v, want %v", err, tt.wantErr)
+			}
+			if got.Tag != tt.want {
+				t.Errorf("oldestSupported() = %q, want %q", got.Tag, tt.want)
+			}
+			if !reflect.DeepEqual(skipped, tt.wantSkipped) {
+				t.Errorf("skipped = %q, want %q", skipped, tt.wantSkipped)
+			}
+		})
+	}
+}
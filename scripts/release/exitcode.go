@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+)
+
+// Exit codes of the release command, for automation to tell failures apart:
+//
+//	1 any other failure
+//	2 invalid input: flags, tags, dates or data files (ErrInvalid,
+//	  ErrNoVersions, ErrNoLatest)
+//	3 I/O error on the repository (ErrDataFileMissing, *fs.PathError)
+//	4 network or upstream error (ErrFetchGoMod, ErrFetchRelease)
+//	5 the version or its folder already exists (ErrAlreadyExists)
+const (
+	exitFailure       = 1
+	exitInvalid       = 2
+	exitIO            = 3
+	exitUpstream      = 4
+	exitAlreadyExists = 5
+)
+
+var (
+	// ErrInvalid is matched by the errors of invalidf
+	ErrInvalid = errors.New("invalid input")
+	// ErrAlreadyExists is returned when the version or its folder already exists
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrFetchGoMod is returned when the go.mod of a release cannot be fetched
+	ErrFetchGoMod = errors.New("failed to fetch go.mod")
+	// ErrFetchRelease is returned when the GitHub release of a version
+	// cannot be fetched
+	ErrFetchRelease = errors.New("failed to fetch the GitHub release")
+)
+
+// invalidInput marks an error as caused by invalid input, keeping its message
+type invalidInput struct {
+	err error
+}
+
+func (e invalidInput) Error() string        { return e.err.Error() }
+func (e invalidInput) Unwrap() error        { return e.err }
+func (e invalidInput) Is(target error) bool { return target == ErrInvalid }
+
+// invalidf formats an error matching ErrInvalid
+func invalidf(format string, args ...any) error {
+	return invalidInput{err: fmt.Errorf(format, args...)}
+}
+
+// exitCode returns the exit code reporting err
+func exitCode(err error) int {
+	var pathErr *fs.PathError
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, ErrAlreadyExists):
+		return exitAlreadyExists
+	case errors.Is(err, ErrFetchGoMod), errors.Is(err, ErrFetchRelease):
+		return exitUpstream
+	case errors.Is(err, ErrInvalid), errors.Is(err, ErrNoVersions), errors.Is(err, ErrNoLatest):
+		return exitInvalid
+	case errors.Is(err, ErrDataFileMissing), errors.As(err, &pathErr):
+		return exitIO
+	}
+	return exitFailure
+}
+
+// exitWithError logs err, in red on a terminal, and exits with its exit code
+func exitWithError(err error) {
+	log.Print(colorize(stderrColor, colorRed, err.Error()))
+	os.Exit(exitCode(err))
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// failingTransport fails every request as a network error would
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T) error
+		want int
+	}{
+		{
+			name: "success",
+			run: func(t *testing.T) error {
+				_, err := addRelease(AddOptions{Root: newTestRepo(t), Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33"})
+				return err
+			},
+			want: 0,
+		},
+		{
+			name: "invalid tag",
+			run: func(t *testing.T) error {
+				_, err := addRelease(AddOptions{Root: newTestRepo(t), Project: "eso", Tag: "latest", TestedK8sVersions: "v1.33"})
+				return err
+			},
+			want: exitInvalid,
+		},
+		{
+			name: "invalid tested k8s version",
+			run: func(t *testing.T) error {
+				_, err := addRelease(AddOptions{Root: newTestRepo(t), Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "1.33"})
+				return err
+			},
+			want: exitInvalid,
+		},
+		{
+			name: "missing data file",
+			run: func(t *testing.T) error {
+				return replaceRelease(Settings{}, t.TempDir(), "eso", "v0.14.0", ReplaceOptions{ReleaseDate: "2025-02-01"})
+			},
+			want: exitIO,
+		},
+		{
+			name: "network failure",
+			run: func(t *testing.T) error {
+				original := httpClient
+				httpClient = &http.Client{Transport: failingTransport{}}
+				t.Cleanup(func() { httpClient = original })
+				_, err := addRelease(AddOptions{Root: newTestRepo(t), Project: "eso", Tag: "v0.15.0"})
+				return err
+			},
+			want: exitUpstream,
+		},
+		{
+			name: "version already exists",
+			run: func(t *testing.T) error {
+				_, err := addRelease(AddOptions{Root: newTestRepo(t), Project: "eso", Tag: "v0.14", TestedK8sVersions: "v1.33"})
+				return err
+			},
+			want: exitAlreadyExists,
+		},
+		{
+			name: "other failure",
+			run: func(*testing.T) error {
+				return errors.New("unexpected")
+			},
+			want: exitFailure,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run(t)
+			if got := exitCode(err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d, want %d", err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidfKeepsMessage(t *testing.T) {
+	cause := errors.New("cause")
+	err := invalidf("bad value: %w", cause)
+	if err.Error() != "bad value: cause" || !errors.Is(err, ErrInvalid) || !errors.Is(err, cause) {
+		t.Errorf("invalidf() = %v, want the message unchanged, matching ErrInvalid and its cause", err)
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakeTransport serves canned go.mod content and records requested URLs
+type fakeTransport struct {
+	body      string
+	requested []string
+}
+
+func (f *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.requested = append(f.requested, req.URL.String())
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(f.body)),
+		Request:    req,
+	}, nil
+}
+
+// useFakeTransport routes httpClient through a fakeTransport for the test
+func useFakeTransport(t *testing.T, body string) *fakeTransport {
+	t.Helper()
+	fake := &fakeTransport{body: body}
+	original := httpClient
+	httpClient = &http.Client{Transport: fake}
+	t.Cleanup(func() { httpClient = original })
+	return fake
+}
+
+func TestResolveGoModURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		rawBaseURL string
+		want       string
+		wantErr    bool
+	}{
+		{
+			name: "default",
+			want: "https://raw.githubusercontent.com/external-secrets/external-secrets/v0.15.0/go.mod",
+		},
+		{
+			name:       "mirror host",
+			rawBaseURL: "http://mirror.internal:8080",
+			want:       "http://mirror.internal:8080/external-secrets/external-secrets/v0.15.0/go.mod",
+		},
+		{
+			name:       "mirror with path prefix",
+			rawBaseURL: "https://ghe.example.com/raw/",
+			want:       "https://ghe.example.com/raw/external-secrets/external-secrets/v0.15.0/go.mod",
+		},
+		{
+			name:       "not an absolute URL",
+			rawBaseURL: "mirror.internal",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveGoModURL("eso", "v0.15.0", tt.rawBaseURL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveGoModURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveGoModURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddReleaseUsesRawBaseURL(t *testing.T) {
+	fake := useFakeTransport(t, sampleGoMod)
+	root := newTestRepo(t)
+
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", RawBaseURL: "https://mirror.internal"}); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+
+	want := "https://mirror.internal/external-secrets/external-secrets/v0.15.0/go.mod"
+	if len(fake.requested) != 1 || fake.requested[0] != want {
+		t.Errorf("requested %v, want [%s]", fake.requested, want)
+	}
+}
+
+func TestResolveGoModURLPath(t *testing.T) {
+	original := projects["eso"]
+	t.Cleanup(func() { projects["eso"] = original })
+
+	tests := []struct {
+		goModPath string
+		want      string
+	}{
+		{goModPath: "", want: "https://raw.githubusercontent.com/external-secrets/external-secrets/v0.15.0/go.mod"},
+		{goModPath: "go.mod", want: "https://raw.githubusercontent.com/external-secrets/external-secrets/v0.15.0/go.mod"},
+		{goModPath: "apis/go.mod", want: "https://raw.githubusercontent.com/external-secrets/external-secrets/v0.15.0/apis/go.mod"},
+		{goModPath: "/apis/../apis/go.mod", want: "https://raw.githubusercontent.com/external-secrets/external-secrets/v0.15.0/apis/go.mod"},
+	}
+	for _, tt := range tests {
+		details := original
+		details.GoModPath = tt.goModPath
+		projects["eso"] = details
+
+		got, err := resolveGoModURL("eso", "v0.15.0", "")
+		if err != nil {
+			t.Fatalf("resolveGoModURL() with %q error = %v", tt.goModPath, err)
+		}
+		if got != tt.want {
+			t.Errorf("resolveGoModURL() with %q = %v, want %v", tt.goModPath, got, tt.want)
+		}
+	}
+}
+
+func TestResolveGoModURLTagPrefix(t *testing.T) {
+	original := projects["eso"]
+	t.Cleanup(func() { projects["eso"] = original })
+	details := original
+	details.TagPrefix = "eso/"
+	projects["eso"] = details
+
+	want := "https://raw.githubusercontent.com/external-secrets/external-secrets/eso/v0.15.0/go.mod"
+	for _, tag := range []string{"v0.15.0", "eso/v0.15.0"} {
+		got, err := resolveGoModURL("eso", tag, "")
+		if err != nil {
+			t.Fatalf("resolveGoModURL(%q) error = %v", tag, err)
+		}
+		if got != want {
+			t.Errorf("resolveGoModURL(%q) = %v, want %v", tag, got, want)
+		}
+	}
+}
+
+// routeTransport serves a go.mod per URL, and 404 for unknown URLs
+type routeTransport map[string]string
+
+func (r routeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, ok := r[req.URL.String()]
+	status := http.StatusOK
+	if !ok {
+		status = http.StatusNotFound
+	}
+	return &http.Response{
+		StatusCode: status,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestAddReleaseHighestClientGo(t *testing.T) {
+	const (
+		coreURL      = "https://example.com/core/go.mod"
+		providersURL = "https://example.com/providers/go.mod"
+	)
+	original := httpClient
+	httpClient = &http.Client{Transport: routeTransport{
+		coreURL:      "module core\n\ngo 1.24.0\n\nrequire k8s.io/client-go v0.33.2\n",
+		providersURL: "module providers\n\ngo 1.25.0\n\nrequire k8s.io/client-go v0.34.1\n",
+	}}
+	t.Cleanup(func() { httpClient = original })
+
+	url, goMod, err := Settings{}.fetchHighestClientGoMod(httpClient, []string{coreURL, providersURL})
+	if err != nil {
+		t.Fatalf("fetchHighestClientGoMod() error = %v", err)
+	}
+	if url != providersURL || !strings.Contains(goMod, "module providers") {
+		t.Errorf("fetchHighestClientGoMod() = %s, want %s", url, providersURL)
+	}
+
+	root := newTestRepo(t)
+	changes, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", GoModURLs: []string{coreURL, providersURL}})
+	if err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	if got := strings.Join(changes.TestedK8sVersions, ","); got != "v1.34" {
+		t.Errorf("tested k8s versions = %s, want v1.34 from the highest client-go", got)
+	}
+	if changes.GoVersion != "1.25.0" {
+		t.Errorf("go version = %s, want the one of the winning go.mod", changes.GoVersion)
+	}
+}
+
+func TestAddReleaseTransport(t *testing.T) {
+	// The default client must not be used once a transport is given
+	original := httpClient
+	httpClient = &http.Client{Transport: failingTransport{}}
+	t.Cleanup(func() { httpClient = original })
+	stub := &fakeTransport{body: sampleGoMod}
+	root := newTestRepo(t)
+
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", Transport: stub}); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	want := "https://raw.githubusercontent.com/external-secrets/external-secrets/v0.15.0/go.mod"
+	if len(stub.requested) != 1 || stub.requested[0] != want {
+		t.Errorf("requested %v, want [%s]", stub.requested, want)
+	}
+	versions, err := readVersions(filepath.Join(root, "data", "eso_versions.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := versions.Versions[0].TestedK8sVersions; !slices.Equal(got, []string{"v1.35"}) {
+		t.Errorf("tested k8s versions = %v, want the ones of the stub go.mod", got)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// DerivedK8sVersions is what add derives from the go.mod of a release
+// when no tested k8s versions are given
+type DerivedK8sVersions struct {
+	GoModURL    string   `json:"go_mod_url"`
+	ClientGo    string   `json:"client_go"`
+	K8sVersions []string `json:"k8s_versions"`
+}
+
+func handleFetchOnly(settings Settings, project string, tag string, rawBaseURL string, goModURLs []string, asJSON bool) {
+	if project == "" || tag == "" {
+		fmt.Print("Missing project or tag\n")
+		printReleaseUsage()
+		os.Exit(1)
+	}
+
+	derived, err := deriveK8sVersions(settings, project, tag, rawBaseURL, goModURLs)
+	if err != nil {
+		exitWithError(err)
+	}
+	if err := printDerivedK8sVersions(os.Stdout, derived, asJSON); err != nil {
+		exitWithError(err)
+	}
+}
+
+// deriveK8sVersions fetches the go.mod of the release, as add does, and
+// derives the tested k8s versions from its client-go, without touching
+// any file
+func deriveK8sVersions(settings Settings, project string, tag string, rawBaseURL string, goModURLs []string) (*DerivedK8sVersions, error) {
+	if err := validateProject(project); err != nil {
+		return nil, err
+	}
+	tag = normalizeVersion(stripTagPrefix(project, tag))
+	if !semver.IsValid(tag) {
+		return nil, invalidf("Invalid semver tag: %s. Use full semver like v0.15.0", tag)
+	}
+	if len(goModURLs) == 0 {
+		goModURL, err := resolveGoModURL(project, tag, rawBaseURL)
+		if err != nil {
+			return nil, err
+		}
+		goModURLs = []string{goModURL}
+	}
+
+	goModURL, goMod, err := settings.fetchHighestClientGoMod(httpClient, goModURLs)
+	if err != nil {
+		return nil, err
+	}
+	clientGo, err := parseK8sClientGoVersion(goMod)
+	if err != nil {
+		return nil, fmt.Errorf("cannot derive the k8s versions of %s from %s: %w", tag, goModURL, err)
+	}
+	return &DerivedK8sVersions{
+		GoModURL:    goModURL,
+		ClientGo:    clientGo,
+		K8sVersions: splitK8sVersions(convertClientGoToRealK8sVersion(clientGo)),
+	}, nil
+}
+
+// printDerivedK8sVersions outputs the derived k8s versions, as text or JSON
+func printDerivedK8sVersions(w io.Writer, d *DerivedK8sVersions, asJSON bool) error {
+	if asJSON {
+		out, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", out)
+		return err
+	}
+	_, err := fmt.Fprintf(w, "go.mod:       %s\nclient-go:    %s\nk8s versions: %s\n", d.GoModURL, d.ClientGo, strings.Join(d.K8sVersions, ","))
+	return err
+}
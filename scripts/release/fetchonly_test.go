@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDeriveK8sVersions(t *testing.T) {
+	fake := useFakeTransport(t, sampleGoMod)
+	root := newTestRepo(t)
+	before := listTree(t, root)
+
+	derived, err := deriveK8sVersions(Settings{}, "eso", "v0.15.0", "http://mirror.internal", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantURL := "http://mirror.internal/external-secrets/external-secrets/v0.15.0/go.mod"
+	want := &DerivedK8sVersions{GoModURL: wantURL, ClientGo: "v0.35.0", K8sVersions: []string{"v1.35"}}
+	if !reflect.DeepEqual(derived, want) {
+		t.Errorf("derived %+v, want %+v", derived, want)
+	}
+	if len(fake.requested) != 1 || fake.requested[0] != wantURL {
+		t.Errorf("requested %v, want [%s]", fake.requested, wantURL)
+	}
+	// Nothing is written
+	if after := listTree(t, root); !reflect.DeepEqual(after, before) {
+		t.Errorf("fetch-only changed the tree: %v, want %v", after, before)
+	}
+
+	var text bytes.Buffer
+	if err := printDerivedK8sVersions(&text, derived, false); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text.String(), "k8s versions: v1.35\n") {
+		t.Errorf("text output:\n%s", text.String())
+	}
+	var out bytes.Buffer
+	if err := printDerivedK8sVersions(&out, derived, true); err != nil {
+		t.Fatal(err)
+	}
+	var decoded DerivedK8sVersions
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out.String(), err)
+	}
+	if !reflect.DeepEqual(&decoded, want) {
+		t.Errorf("JSON %+v, want %+v", decoded, want)
+	}
+}
+
+func TestDeriveK8sVersionsInvalidTag(t *testing.T) {
+	fake := useFakeTransport(t, sampleGoMod)
+	newTestRepo(t)
+
+	if _, err := deriveK8sVersions(Settings{}, "eso", "latest", "", nil); !errors.Is(err, ErrInvalid) {
+		t.Errorf("got %v, want ErrInvalid", err)
+	}
+	if len(fake.requested) != 0 {
+		t.Errorf("fetched %v for an invalid tag", fake.requested)
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fixtureLandingPage is a version landing page as the site has them
+func fixtureLandingPage(version string, weight int) string {
+	return fmt.Sprintf(`+++
+title = "External-Secrets Operator Documentation (%[1]s)"
+linkTitle = "ESO Documentation (%[1]s)"
+weight = %[2]d
+sidebar_root_for = "self"
+
+[[cascade]]
+type = "docs"
+
+  [cascade.params]
+  project = "eso"
+  project_version = "%[1]s"
++++
+`, version, weight)
+}
+
+// writeFiles writes files, keyed by their slash separated path relative to
+// root, creating the folders they are in
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// setupFixture creates a checkout laid out like the website, unlike the
+// minimal newTestRepo: both projects, several released eso versions with
+// their folders, and unreleased content spread over nested sections.
+// v0.14.1 is the latest eso version. It returns the root.
+func setupFixture(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"data/eso_versions.toml": `[[versions]]
+  tag = "v0.14.1"
+  latest = true
+  release_date = "2025-02-01"
+  tested_k8s_versions = ["v1.31", "v1.32"]
+  end_of_life = ""
+
+[[versions]]
+  tag = "v0.14.0"
+  latest = false
+  release_date = "2025-01-01"
+  tested_k8s_versions = ["v1.31", "v1.32"]
+  end_of_life = ""
+
+[[versions]]
+  tag = "v0.13.0"
+  latest = false
+  release_date = "2024-10-01"
+  tested_k8s_versions = ["v1.30", "v1.31"]
+  end_of_life = "2025-04-01"
+`,
+		"data/reloader_versions.toml": `[[versions]]
+  tag = "v1.0.0"
+  latest = true
+  release_date = "2025-01-15"
+  tested_k8s_versions = ["v1.32"]
+  end_of_life = ""
+`,
+		"content/en/eso-docs/_index.md": `+++
+title = "Operator Documentation"
+linkTitle = "Operator Docs"
+type = "redirect"
+
+[menu.main]
+weight = 10
+
+[[cascade]]
+type = "docs"
+
+[cascade.params]
+project = "eso"
++++
+`,
+		"content/en/eso-docs/v0.13/_index.md":                            fixtureLandingPage("v0.13", 2),
+		"content/en/eso-docs/v0.13/guides/getting-started.md":            "# Getting started\n",
+		"content/en/eso-docs/v0.14/_index.md":                            fixtureLandingPage("v0.14", 1),
+		"content/en/eso-docs/v0.14/guides/getting-started.md":            "# Getting started\n",
+		"content/en/eso-docs/unreleased/_index.md":                       strings.Replace(fixtureLandingPage("unreleased", 1), "Documentation (unreleased)", "Documentation (Unreleased)", 1),
+		"content/en/eso-docs/unreleased/guides/getting-started.md":       "# Getting started\n\nInstall the unreleased chart.\n",
+		"content/en/eso-docs/unreleased/provider/aws/_index.md":          "+++\ntitle = \"AWS\"\n+++\n",
+		"content/en/eso-docs/unreleased/provider/aws/secrets-manager.md": "# AWS Secrets Manager\n",
+		"content/en/eso-docs/unreleased/pictures/diagram.png":            "\x89PNG\r\n",
+		"content/en/reloader-docs/_index.md":                             "+++\ntitle = \"Reloader Documentation\"\n+++\n",
+		"content/en/reloader-docs/unreleased/_index.md":                  "+++\ntitle = \"Reloader Documentation (Unreleased)\"\n+++\n",
+	}
+	writeFiles(t, root, files)
+	return root
+}
+
+func TestEndToEndAddAndRemove(t *testing.T) {
+	root := setupFixture(t)
+	baseDir := filepath.Join(root, "content", "en", "eso-docs")
+	dataFile := Settings{}.dataFilePath(root, "eso")
+	rootIndex, err := os.ReadFile(filepath.Join(baseDir, "_index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", ReleaseDate: "2025-03-01", TestedK8sVersions: "v1.32,v1.33"}); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+
+	versions, err := readVersions(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tags []string
+	for _, v := range versions.Versions {
+		tags = append(tags, fmt.Sprintf("%s latest=%t", v.Tag, v.Latest))
+	}
+	want := []string{"v0.15.0 latest=true", "v0.14.1 latest=false", "v0.14.0 latest=false", "v0.13.0 latest=false"}
+	if strings.Join(tags, "\n") != strings.Join(want, "\n") {
+		t.Errorf("versions = %q, want %q", tags, want)
+	}
+	if got := versions.Versions[0]; got.ReleaseDate != "2025-03-01" || strings.Join(got.TestedK8sVersions, ",") != "v1.32,v1.33" {
+		t.Errorf("new version = %+v, want its release date and tested k8s versions", got)
+	}
+
+	for _, name := range []string{"guides/getting-started.md", "provider/aws/_index.md", "provider/aws/secrets-manager.md", "pictures/diagram.png"} {
+		copied, err := os.ReadFile(filepath.Join(baseDir, "v0.15", name))
+		if err != nil {
+			t.Errorf("%s was not copied: %v", name, err)
+			continue
+		}
+		source, err := os.ReadFile(filepath.Join(baseDir, "unreleased", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(copied) != string(source) {
+			t.Errorf("v0.15/%s = %q, want the unreleased content %q", name, copied, source)
+		}
+	}
+	landing, err := os.ReadFile(filepath.Join(baseDir, "v0.15", "_index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`title = "External-Secrets Operator Documentation (v0.15)"`, `project_version = "v0.15"`, "weight = 1\n"} {
+		if !strings.Contains(string(landing), want) {
+			t.Errorf("v0.15/_index.md = %s, want %s", landing, want)
+		}
+	}
+	for version, weight := range map[string]int{"v0.14": 2, "v0.13": 3} {
+		page, err := os.ReadFile(filepath.Join(baseDir, version, "_index.md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := fmt.Sprintf("weight = %d\n", weight); !strings.Contains(string(page), want) {
+			t.Errorf("%s/_index.md = %s, want %s", version, page, want)
+		}
+	}
+	if after, err := os.ReadFile(filepath.Join(baseDir, "_index.md")); err != nil || string(after) != string(rootIndex) {
+		t.Errorf("root index changed to %s (%v), want it untouched", after, err)
+	}
+
+	if _, err := removeRelease(Settings{}, root, "eso", "v0.15.0", false); err != nil {
+		t.Fatalf("removeRelease() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(baseDir, "v0.15")); !os.IsNotExist(err) {
+		t.Errorf("v0.15 still exists after the removal, stat error = %v", err)
+	}
+	if versions, err = readVersions(dataFile); err != nil {
+		t.Fatal(err)
+	}
+	if len(versions.Versions) != 3 || findDuplicateTag("v0.15.0", versions.Versions) != "" {
+		t.Errorf("versions after the removal = %+v, want v0.15.0 removed", versions.Versions)
+	}
+	// Removing the latest version does not promote another one
+	if _, err := latestIndex(versions.Versions); !errors.Is(err, ErrNoLatest) {
+		t.Errorf("latestIndex() after the removal error = %v, want ErrNoLatest", err)
+	}
+}
+
+func TestRemoveReleaseKeepContent(t *testing.T) {
+	root := setupFixture(t)
+	versionDir := filepath.Join(root, "content", "en", "eso-docs", "v0.13")
+
+	removed, err := removeRelease(Settings{}, root, "eso", "v0.13.0", true)
+	if err != nil {
+		t.Fatalf("removeRelease() error = %v", err)
+	}
+	if removed.Tag != "v0.13.0" {
+		t.Errorf("removeRelease() = %+v, want v0.13.0", removed)
+	}
+	versions, err := readVersions(Settings{}.dataFilePath(root, "eso"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if existing := findDuplicateTag("v0.13.0", versions.Versions); existing != "" {
+		t.Errorf("versions = %+v, want v0.13.0 removed", versions.Versions)
+	}
+	for _, name := range []string{"_index.md", "guides/getting-started.md"} {
+		if _, err := os.Stat(filepath.Join(versionDir, name)); err != nil {
+			t.Errorf("v0.13/%s did not survive --keep-content: %v", name, err)
+		}
+	}
+}
+
+func TestAddReleaseBeta(t *testing.T) {
+	root := setupFixture(t)
+	dataFile := filepath.Join(root, "data", "eso_versions.toml")
+	latestOf := func() string {
+		t.Helper()
+		versions, err := readVersions(dataFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		i, err := latestIndex(versions.Versions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return versions.Versions[i].Tag
+	}
+
+	// A stable tag cannot be a beta
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33", Beta: true}); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("addRelease() of a stable beta error = %v, want ErrInvalid", err)
+	}
+
+	changes, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0-rc.1", ReleaseDate: "2025-03-01", TestedK8sVersions: "v1.33", Beta: true})
+	if err != nil {
+		t.Fatalf("addRelease() beta error = %v", err)
+	}
+	if got := latestOf(); got != "v0.14.1" {
+		t.Errorf("latest after a beta = %s, want v0.14.1 untouched", got)
+	}
+	if md := changes.Markdown(); !strings.Contains(md, "New beta: `v0.15.0-rc.1`") || !strings.Contains(md, "Latest: `v0.14.1` (unchanged)") {
+		t.Errorf("summary does not report the beta:\n%s", md)
+	}
+	versions, err := readVersions(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The root index links the first prerelease listed before the latest
+	if first := versions.Versions[0]; first.Tag != "v0.15.0-rc.1" || first.Latest {
+		t.Errorf("first version = %+v, want the beta, not latest", first)
+	}
+	html := renderRedirectLayout(t, root, "eso")
+	for _, want := range []string{
+		`<a href="/eso-docs/v0.14/">/eso-docs/v0.14/</a>`,
+		`The v0.15.0-rc.1 pre-release is available in the <a href="/eso-docs/v0.15/">beta documentation</a>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("root index with a beta does not contain %q:\n%s", want, html)
+		}
+	}
+
+	// A stable release takes the latest link, and the beta listed after it
+	// is no longer linked
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", ReleaseDate: "2025-03-15", TestedK8sVersions: "v1.33"}); err != nil {
+		t.Fatalf("addRelease() stable error = %v", err)
+	}
+	if got := latestOf(); got != "v0.15.0" {
+		t.Errorf("latest after the stable release = %s, want v0.15.0", got)
+	}
+	html = renderRedirectLayout(t, root, "eso")
+	if want := `<a href="/eso-docs/v0.15/">/eso-docs/v0.15/</a>`; !strings.Contains(html, want) {
+		t.Errorf("root index after the stable release does not contain %q:\n%s", want, html)
+	}
+	if strings.Contains(html, "beta documentation") {
+		t.Errorf("root index still links the beta listed after the latest:\n%s", html)
+	}
+}
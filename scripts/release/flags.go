@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// stringList is a repeatable string flag
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// octalMode is a permission bits flag given in octal, e.g. 0664
+type octalMode struct {
+	mode *fs.FileMode
+}
+
+func (m octalMode) String() string {
+	if m.mode == nil {
+		return ""
+	}
+	return fmt.Sprintf("%#o", *m.mode)
+}
+
+func (m octalMode) Set(value string) error {
+	bits, err := strconv.ParseUint(value, 8, 32)
+	if err != nil || bits&^uint64(fs.ModePerm) != 0 {
+		return fmt.Errorf("expected octal permission bits such as 0664, got %q", value)
+	}
+	*m.mode = fs.FileMode(bits)
+	return nil
+}
+
+// flagEnvPrefix prefixes the environment variables giving flags their
+// default value
+const flagEnvPrefix = "RELEASE_"
+
+// flagEnvName returns the environment variable of the flag name,
+// e.g. RELEASE_TESTED_K8S_VERSIONS for --tested-k8s-versions
+func flagEnvName(name string) string {
+	return flagEnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// applyFlagEnv sets the flags not given on the command line from their
+// environment variable, when it is set. Explicit flags take precedence.
+func applyFlagEnv(flags *flag.FlagSet) error {
+	explicit := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+	var err error
+	flags.VisitAll(func(f *flag.Flag) {
+		value, ok := os.LookupEnv(flagEnvName(f.Name))
+		if err != nil || explicit[f.Name] || !ok || value == "" {
+			return
+		}
+		if setErr := flags.Set(f.Name, value); setErr != nil {
+			err = invalidf("invalid value %q for %s: %w", value, flagEnvName(f.Name), setErr)
+		}
+	})
+	return err
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"io/fs"
+	"testing"
+)
+
+func TestApplyFlagEnv(t *testing.T) {
+	t.Setenv("RELEASE_PROJECT", "eso")
+	t.Setenv("RELEASE_TAG", "v0.15.0")
+	t.Setenv("RELEASE_TESTED_K8S_VERSIONS", "v1.32,v1.33")
+	t.Setenv("RELEASE_DRY_RUN", "true")
+	t.Setenv("RELEASE_RELEASE_DATE", "")
+
+	flags := flag.NewFlagSet("release", flag.ContinueOnError)
+	project := flags.String("project", "", "")
+	tag := flags.String("tag", "", "")
+	releaseDate := flags.String("release-date", "", "")
+	testedK8sVersions := flags.String("tested-k8s-versions", "", "")
+	dryRun := flags.Bool("dry-run", false, "")
+	if err := flags.Parse([]string{"--tag", "v0.16.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := applyFlagEnv(flags); err != nil {
+		t.Fatalf("applyFlagEnv() error = %v", err)
+	}
+	if *project != "eso" || *testedK8sVersions != "v1.32,v1.33" || !*dryRun {
+		t.Errorf("flags = %s, %s, %t, want them from the environment", *project, *testedK8sVersions, *dryRun)
+	}
+	if *tag != "v0.16.0" {
+		t.Errorf("tag = %s, want the explicit flag v0.16.0", *tag)
+	}
+	if *releaseDate != "" {
+		t.Errorf("release date = %s, want the default for an empty variable", *releaseDate)
+	}
+}
+
+func TestApplyFlagEnvInvalid(t *testing.T) {
+	t.Setenv("RELEASE_DRY_RUN", "maybe")
+	flags := flag.NewFlagSet("release", flag.ContinueOnError)
+	flags.Bool("dry-run", false, "")
+	if err := flags.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyFlagEnv(flags); exitCode(err) != exitInvalid {
+		t.Errorf("applyFlagEnv() error = %v, want invalid input", err)
+	}
+}
+
+func TestFlagEnvName(t *testing.T) {
+	if got := flagEnvName("raw-base-url"); got != rawBaseURLEnv {
+		t.Errorf("flagEnvName() = %s, want %s", got, rawBaseURLEnv)
+	}
+}
+
+func TestOctalMode(t *testing.T) {
+	var mode fs.FileMode = 0644
+	flags := flag.NewFlagSet("release", flag.ContinueOnError)
+	flags.Var(octalMode{&mode}, "file-mode", "")
+
+	if err := flags.Parse([]string{"--file-mode", "0664"}); err != nil {
+		t.Fatal(err)
+	}
+	if mode != 0664 {
+		t.Errorf("mode = %#o, want 0664", mode)
+	}
+	for _, value := range []string{"664x", "0999", "01777", "-1"} {
+		if err := (octalMode{&mode}).Set(value); err == nil {
+			t.Errorf("Set(%q) succeeded, want an error", value)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// FS is the filesystem CopyDir reads from and writes to.
+// It allows testing the copy against an in-memory filesystem.
+type FS interface {
+	Lstat(name string) (fs.FileInfo, error)
+	ReadDir(name string) ([]fs.DirEntry, error)
+	Open(name string) (io.ReadCloser, error)
+	// Create truncates or creates name with the given permission bits
+	Create(name string, mode fs.FileMode) (io.WriteCloser, error)
+	MkdirAll(path string, mode fs.FileMode) error
+	Chmod(name string, mode fs.FileMode) error
+	Chtimes(name string, atime, mtime time.Time) error
+	Readlink(name string) (string, error)
+	Symlink(oldname, newname string) error
+	Remove(name string) error
+}
+
+// osFS is the FS backed by the operating system
+type osFS struct{}
+
+func (osFS) Lstat(name string) (fs.FileInfo, error)     { return os.Lstat(name) }
+func (osFS) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+func (osFS) Open(name string) (io.ReadCloser, error)    { return os.Open(name) }
+func (osFS) Create(name string, mode fs.FileMode) (io.WriteCloser, error) {
+	return os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+}
+func (osFS) MkdirAll(path string, mode fs.FileMode) error      { return os.MkdirAll(path, mode) }
+func (osFS) Chmod(name string, mode fs.FileMode) error         { return os.Chmod(name, mode) }
+func (osFS) Chtimes(name string, atime, mtime time.Time) error { return os.Chtimes(name, atime, mtime) }
+func (osFS) Readlink(name string) (string, error)              { return os.Readlink(name) }
+func (osFS) Symlink(oldname, newname string) error             { return os.Symlink(oldname, newname) }
+func (osFS) Remove(name string) error                          { return os.Remove(name) }
+
+// walkDir is filepath.WalkDir on top of an FS: it walks root in lexical
+// order, calling fn for each file or directory, without following symlinks.
+func walkDir(fsys FS, root string, fn fs.WalkDirFunc) error {
+	info, err := fsys.Lstat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = walkDirEntry(fsys, root, fs.FileInfoToDirEntry(info), fn)
+	}
+	if err == filepath.SkipDir || err == filepath.SkipAll {
+		return nil
+	}
+	return err
+}
+
+func walkDirEntry(fsys FS, path string, d fs.DirEntry, fn fs.WalkDirFunc) error {
+	if err := fn(path, d, nil); err != nil || !d.IsDir() {
+		if err == filepath.SkipDir && d.IsDir() {
+			err = nil
+		}
+		return err
+	}
+
+	entries, err := fsys.ReadDir(path)
+	if err != nil {
+		// Second call, to report the ReadDir error
+		err = fn(path, d, err)
+		if err != nil {
+			if err == filepath.SkipDir && d.IsDir() {
+				err = nil
+			}
+			return err
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	for _, entry := range entries {
+		if err := walkDirEntry(fsys, filepath.Join(path, entry.Name()), entry, fn); err != nil {
+			if err == filepath.SkipDir {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// writeGeneratedFile writes data to name with the file mode of the
+// settings, whatever the umask or the mode name had, e.g. as a copied
+// landing page
+func (s Settings) writeGeneratedFile(name string, data []byte) error {
+	if err := os.WriteFile(name, data, s.fileMode()); err != nil {
+		return err
+	}
+	return os.Chmod(name, s.fileMode())
+}
+
+// mkdirGenerated creates the folder path and its parents. The folders it
+// creates get the folder mode of the settings whatever the umask, existing
+// ones keep their mode.
+func (s Settings) mkdirGenerated(path string) error {
+	var created []string
+	for dir := filepath.Clean(path); ; dir = filepath.Dir(dir) {
+		if _, err := os.Lstat(dir); !os.IsNotExist(err) {
+			break
+		}
+		created = append(created, dir)
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	if err := os.MkdirAll(path, s.dirMode()); err != nil {
+		return err
+	}
+	for _, dir := range created {
+		if err := os.Chmod(dir, s.dirMode()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
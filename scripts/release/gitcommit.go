@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// gitCommitMessage is the message of the --git-commit commit
+func gitCommitMessage(project, tag string) (string, error) {
+	majorMinor, err := extractMajorMinor(tag)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("docs(%s): add %s", project, majorMinor), nil
+}
+
+// checkStagedChanges fails when changes are already staged in the git
+// repository at root, as --git-commit would commit them with the release
+func checkStagedChanges(root string) error {
+	out, err := runCommand(root, "git", "diff", "--cached", "--name-only")
+	if err != nil {
+		return err
+	}
+	if staged := strings.Fields(out); len(staged) > 0 {
+		return fmt.Errorf("changes unrelated to the release are staged (%s), commit or unstage them, or use --git-allow-dirty", strings.Join(staged, ", "))
+	}
+	return nil
+}
+
+// gitCommitPaths returns the paths of changes to stage, relative to the
+// repository root: the data file, the version folder as a whole rather
+// than each of its files, the other created or modified files and the
+// pruned folders.
+func gitCommitPaths(changes *Changeset) []string {
+	var paths []string
+	add := func(p string) {
+		if p != "" && !slices.Contains(paths, p) {
+			paths = append(paths, p)
+		}
+	}
+	add(changes.DataFile)
+	add(changes.VersionDir)
+	for _, file := range slices.Concat(changes.FilesCreated, changes.FilesModified) {
+		if changes.VersionDir != "" && strings.HasPrefix(file, changes.VersionDir+"/") {
+			continue
+		}
+		add(path.Clean(file))
+	}
+	for _, folder := range changes.FoldersRemoved {
+		add(folder)
+	}
+	return paths
+}
+
+// gitCommit stages the files of changes in the git repository at root and
+// commits them, and only them, with gitCommitMessage
+func gitCommit(root string, changes *Changeset) error {
+	message, err := gitCommitMessage(changes.Project, changes.NewLatest)
+	if err != nil {
+		return err
+	}
+	paths := gitCommitPaths(changes)
+	if _, err := runCommand(root, "git", slices.Concat([]string{"add", "--all", "--"}, paths)...); err != nil {
+		return fmt.Errorf("Failed to stage the release: %w", err)
+	}
+	if _, err := runCommand(root, "git", slices.Concat([]string{"commit", "--message", message, "--"}, paths)...); err != nil {
+		return fmt.Errorf("Failed to commit the release: %w", err)
+	}
+	printStep("Committed %q", message)
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGitCommit(t *testing.T) {
+	ran := useFakeCommand(t, nil)
+	changes := &Changeset{
+		Project:        "eso",
+		NewLatest:      "v0.15.2",
+		DataFile:       "data/eso_versions.toml",
+		VersionDir:     "content/en/eso-docs/v0.15",
+		FilesCreated:   []string{"content/en/eso-docs/v0.15/_index.md", "content/en/eso-docs/v0.15/guide/page.md", "content/en/eso-docs/_index.md"},
+		FilesModified:  []string{"data/eso_versions.toml", "content/en/eso-docs/v0.14/_index.md"},
+		FoldersRemoved: []string{"content/en/eso-docs/v0.10"},
+	}
+
+	if err := gitCommit("", changes); err != nil {
+		t.Fatalf("gitCommit() error = %v", err)
+	}
+	paths := "data/eso_versions.toml content/en/eso-docs/v0.15 content/en/eso-docs/_index.md content/en/eso-docs/v0.14/_index.md content/en/eso-docs/v0.10"
+	want := []string{
+		"git add --all -- " + paths,
+		"git commit --message docs(eso): add v0.15 -- " + paths,
+	}
+	if !slices.Equal(*ran, want) {
+		t.Errorf("ran\n%s\nwant\n%s", strings.Join(*ran, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestGitCommitFailure(t *testing.T) {
+	useFakeCommand(t, map[string]fakeOutput{"": {err: errors.New("git: not a git repository")}})
+
+	err := gitCommit("", &Changeset{Project: "eso", NewLatest: "v0.15.0", DataFile: "data/eso_versions.toml"})
+	if err == nil || !strings.Contains(err.Error(), "stage") {
+		t.Errorf("gitCommit() error = %v, want a failure to stage", err)
+	}
+}
+
+func TestCheckStagedChanges(t *testing.T) {
+	const diff = "git diff --cached --name-only"
+
+	ran := useFakeCommand(t, map[string]fakeOutput{diff: {}})
+	if err := checkStagedChanges(""); err != nil {
+		t.Errorf("checkStagedChanges() error = %v, want nil without staged changes", err)
+	}
+	if !slices.Equal(*ran, []string{diff}) {
+		t.Errorf("ran %q, want %q", *ran, diff)
+	}
+
+	useFakeCommand(t, map[string]fakeOutput{diff: {out: "README.md\nlayouts/index.html\n"}})
+	err := checkStagedChanges("")
+	if err == nil || !strings.Contains(err.Error(), "README.md, layouts/index.html") {
+		t.Errorf("checkStagedChanges() error = %v, want the staged files listed", err)
+	}
+}
+
+func TestAddReleaseRecordsWeightChanges(t *testing.T) {
+	useFakeTransport(t, sampleGoMod)
+	root := setupFixture(t)
+
+	changes, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33"})
+	if err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	if changes.VersionDir != "content/en/eso-docs/v0.15" {
+		t.Errorf("VersionDir = %q, want content/en/eso-docs/v0.15", changes.VersionDir)
+	}
+	// The older versions move down the sidebar
+	for _, page := range []string{"content/en/eso-docs/v0.14/_index.md", "content/en/eso-docs/v0.13/_index.md"} {
+		if !slices.Contains(changes.FilesModified, page) {
+			t.Errorf("FilesModified = %v, want %s", changes.FilesModified, page)
+		}
+	}
+}
+
+func TestGitCommitPathsJSONMirrorAndLatestLink(t *testing.T) {
+	root := newTestRepo(t)
+
+	changes, err := addRelease(AddOptions{Settings: Settings{EmitJSON: true}, Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33", SymlinkLatest: true})
+	if err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	paths := gitCommitPaths(changes)
+	for _, want := range []string{"data/eso_versions.json", "content/en/eso-docs/" + latestLink} {
+		if !slices.Contains(paths, want) {
+			t.Errorf("gitCommitPaths() = %v, want %s", paths, want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// githubRefNameEnv names the variable GitHub Actions sets to the branch or
+// tag being built
+const githubRefNameEnv = "GITHUB_REF_NAME"
+
+// runCommand runs name with args in dir and returns its standard output,
+// it is replaced in tests
+var runCommand = func(dir string, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %w", name, strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
+
+// tagFromGit returns the tag of the commit checked out in dir or, when it
+// has none, GITHUB_REF_NAME, without its prefix. The rest of the tag must
+// be a semver version.
+func tagFromGit(dir string, prefix string) (string, error) {
+	out, err := runCommand(dir, "git", "describe", "--tags", "--exact-match")
+	tag := strings.TrimSpace(out)
+	if err != nil || tag == "" {
+		tag = os.Getenv(githubRefNameEnv)
+		if tag == "" {
+			return "", fmt.Errorf("cannot determine the version from git: %w", err)
+		}
+	}
+	tag = strings.TrimPrefix(tag, prefix)
+	if !semver.IsValid(normalizeVersion(tag)) {
+		return "", invalidf("the version from git is not a semver tag: %s", tag)
+	}
+	return tag, nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeOutput is what a faked command returns
+type fakeOutput struct {
+	out string
+	err error
+}
+
+// useFakeCommand makes runCommand answer with outputs[command], the command
+// and its arguments joined by spaces, or outputs[""] for the commands not
+// listed. It records each command run.
+func useFakeCommand(t *testing.T, outputs map[string]fakeOutput) *[]string {
+	t.Helper()
+	var ran []string
+	original := runCommand
+	runCommand = func(dir string, name string, args ...string) (string, error) {
+		command := strings.Join(append([]string{name}, args...), " ")
+		ran = append(ran, command)
+		output, ok := outputs[command]
+		if !ok {
+			output = outputs[""]
+		}
+		return output.out, output.err
+	}
+	t.Cleanup(func() { runCommand = original })
+	return &ran
+}
+
+func TestTagFromGit(t *testing.T) {
+	tests := []struct {
+		name    string
+		out     string
+		err     error
+		refName string
+		want    string
+		wantErr bool
+	}{
+		{name: "tag of the commit", out: "v0.15.3\n", refName: "main", want: "v0.15.3"},
+		{name: "GITHUB_REF_NAME fallback", err: errors.New("no tag exactly matches"), refName: "v0.16.0", want: "v0.16.0"},
+		{name: "no tag", err: errors.New("no tag exactly matches"), wantErr: true},
+		{name: "not semver", err: errors.New("no tag exactly matches"), refName: "main", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ran := useFakeCommand(t, map[string]fakeOutput{"": {tt.out, tt.err}})
+			t.Setenv(githubRefNameEnv, tt.refName)
+
+			got, err := tagFromGit("", "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("tagFromGit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("tagFromGit() = %q, want %q", got, tt.want)
+			}
+			if len(*ran) == 0 || !strings.HasPrefix((*ran)[0], "git describe") {
+				t.Errorf("ran %q, want git describe", *ran)
+			}
+		})
+	}
+}
+
+func TestTagFromGitPrefix(t *testing.T) {
+	useFakeCommand(t, map[string]fakeOutput{"": {out: "reloader/v0.5.0\n"}})
+	got, err := tagFromGit("", "reloader/")
+	if err != nil {
+		t.Fatalf("tagFromGit() error = %v", err)
+	}
+	if got != "v0.5.0" {
+		t.Errorf("tagFromGit() = %q, want v0.5.0", got)
+	}
+}
+
+func TestTagFromGitUsedByAdd(t *testing.T) {
+	useFakeCommand(t, map[string]fakeOutput{"": {out: "v0.15.3\n"}})
+	tag, err := tagFromGit("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := newTestRepo(t)
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: tag, TestedK8sVersions: "v1.33"}); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	versions, err := readVersions(Settings{}.dataFilePath(root, "eso"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := versions.Versions[0].Tag; got != "v0.15.3" {
+		t.Errorf("latest tag = %s, want the git tag v0.15.3", got)
+	}
+}
@@ -4,4 +4,10 @@ go 1.26.0
 
 require github.com/BurntSushi/toml v1.6.0
 
-require golang.org/x/mod v0.33.0 // indirect
+require golang.org/x/mod v0.33.0
+
+require go.yaml.in/yaml/v3 v3.0.4
+
+require github.com/fsnotify/fsnotify v1.10.1
+
+require golang.org/x/sys v0.13.0 // indirect
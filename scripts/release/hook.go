@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// runPostHook runs hook, a command and its space separated arguments, in
+// dir with its output streamed to stdout and stderr.
+// The command is not run through a shell.
+func runPostHook(dir string, hook string, stdout, stderr io.Writer) error {
+	args := strings.Fields(hook)
+	if len(args) == 0 {
+		return nil
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	fmt.Fprintf(stdout, "Running post hook: %s\n", hook)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("post hook %q failed: %w", hook, err)
+	}
+	return nil
+}
+
+// exitWithHookError exits with the exit code of a failed post hook, or 1
+// when it could not be run
+func exitWithHookError(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		os.Exit(exitErr.ExitCode())
+	}
+	os.Exit(1)
+}
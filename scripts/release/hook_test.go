@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunPostHook(t *testing.T) {
+	root := t.TempDir()
+	script := filepath.Join(t.TempDir(), "hook.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ntouch \"marker-$1\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runPostHook(root, script+" done", io.Discard, io.Discard); err != nil {
+		t.Fatalf("runPostHook() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "marker-done")); err != nil {
+		t.Errorf("hook did not run in the repository root: %v", err)
+	}
+}
+
+func TestRunPostHookFailure(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "hook.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nexit 3\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	err := runPostHook(t.TempDir(), script, io.Discard, io.Discard)
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Errorf("runPostHook() error = %v, want exit code 3", err)
+	}
+}
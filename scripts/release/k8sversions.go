@@ -0,0 +1,135 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// defaultMinK8sMinor is the oldest kubernetes minor accepted as a tested
+// version by default
+const defaultMinK8sMinor = 16
+
+// k8sCeilingMargin is how many minors a tested version may be ahead of the
+// newest known one, as releases are sometimes tested against pre-releases
+const k8sCeilingMargin = 2
+
+var k8sVersion = regexp.MustCompile(`^v1\.(0|[1-9][0-9]*)$`)
+
+// k8sMinor returns the minor of a tested k8s version such as v1.33
+func k8sMinor(version string) (int, error) {
+	m := k8sVersion.FindStringSubmatch(version)
+	if m == nil {
+		return 0, invalidf("invalid tested k8s version %q, expected v1.X (e.g. v1.33)", version)
+	}
+	return strconv.Atoi(m[1])
+}
+
+// highestK8sMinor returns the highest minor among the tested k8s versions
+// of versions, or 0 when none is valid
+func highestK8sMinor(versions []Version) int {
+	highest := 0
+	for _, v := range versions {
+		for _, tested := range v.TestedK8sVersions {
+			if minor, err := k8sMinor(tested); err == nil && minor > highest {
+				highest = minor
+			}
+		}
+	}
+	return highest
+}
+
+// k8sCeiling returns the newest tested k8s minor accepted for a new
+// release: k8sCeilingMargin minors ahead of the newest one known from the
+// data file or the client-go of goMod, or 0 when nothing is known.
+func k8sCeiling(versions []Version, goMod string) int {
+	known := highestK8sMinor(versions)
+	if goMod != "" {
+		if clientGo, err := parseK8sClientGoVersion(goMod); err == nil {
+			if minor, err := k8sMinor(convertClientGoToRealK8sVersion(clientGo)); err == nil && minor > known {
+				known = minor
+			}
+		}
+	}
+	if known == 0 {
+		return 0
+	}
+	return known + k8sCeilingMargin
+}
+
+// splitK8sVersions splits a comma separated list of k8s versions, ignoring
+// blank entries, so that a list without any version is nil rather than [""]
+func splitK8sVersions(list string) []string {
+	var versions []string
+	for _, version := range strings.Split(list, ",") {
+		if version = strings.TrimSpace(version); version != "" {
+			versions = append(versions, version)
+		}
+	}
+	return versions
+}
+
+// validateTestedK8sVersions checks that each tested version is v1.X with
+// minMinor <= X <= maxMinor. A zero maxMinor disables the ceiling.
+func validateTestedK8sVersions(tested []string, minMinor, maxMinor int) error {
+	for _, version := range tested {
+		minor, err := k8sMinor(version)
+		if err != nil {
+			return err
+		}
+		if minor < minMinor {
+			return invalidf("tested k8s version %s is older than v1.%d, use --min-k8s-minor if this is intended", version, minMinor)
+		}
+		if maxMinor > 0 && minor > maxMinor {
+			return invalidf("tested k8s version %s is newer than v1.%d, use --max-k8s-minor if this is intended", version, maxMinor)
+		}
+	}
+	return nil
+}
+
+// checkK8sVersionWindow fails when tested does not include derived, the k8s
+// version matching the release's client-go, which usually means the tested
+// versions were copied from a previous release.
+func checkK8sVersionWindow(tested []string, derived string) error {
+	for _, version := range tested {
+		if version == derived {
+			return nil
+		}
+	}
+	return invalidf("tested k8s versions %s do not include %s, the version of the release's client-go", strings.Join(tested, ","), derived)
+}
+
+// normalizeK8sVersion returns version as v1.X when it is a valid semver,
+// e.g. 1.33.0 becomes v1.33
+func normalizeK8sVersion(version string) string {
+	version = normalizeVersion(version)
+	if semver.IsValid(version) {
+		return semver.MajorMinor(version)
+	}
+	return version
+}
+
+// diffK8sVersions returns the tested k8s versions of next missing from
+// previous and those of previous missing from next, sorted by version.
+// Versions are compared normalized, so 1.33 and v1.33.0 are the same.
+func diffK8sVersions(previous, next []string) (added, removed []string) {
+	missingFrom := func(from, in []string) []string {
+		seen := map[string]bool{}
+		for _, v := range in {
+			seen[normalizeK8sVersion(v)] = true
+		}
+		var missing []string
+		for _, v := range from {
+			v = normalizeK8sVersion(v)
+			if !seen[v] {
+				seen[v] = true
+				missing = append(missing, v)
+			}
+		}
+		semver.Sort(missing)
+		return missing
+	}
+	return missingFrom(next, previous), missingFrom(previous, next)
+}
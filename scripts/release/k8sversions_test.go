@@ -0,0 +1,143 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateTestedK8sVersions(t *testing.T) {
+	tests := []struct {
+		name    string
+		tested  []string
+		wantErr bool
+	}{
+		{name: "valid", tested: []string{"v1.32", "v1.33"}},
+		{name: "at the ceiling", tested: []string{"v1.35"}},
+		{name: "implausibly high minor", tested: []string{"v1.350"}, wantErr: true},
+		{name: "below the minimum", tested: []string{"v1.15"}, wantErr: true},
+		{name: "client-go version", tested: []string{"v0.35"}, wantErr: true},
+		{name: "malformed token", tested: []string{"v1.33", " v1.34"}, wantErr: true},
+		{name: "patch version", tested: []string{"v1.33.1"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTestedK8sVersions(tt.tested, defaultMinK8sMinor, 35)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateTestedK8sVersions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestK8sCeiling(t *testing.T) {
+	versions := []Version{{Tag: "v0.14.0", TestedK8sVersions: []string{"v1.31", "v1.32"}}}
+	if got := k8sCeiling(versions, ""); got != 34 {
+		t.Errorf("k8sCeiling() = %d, want 34", got)
+	}
+	// client-go v0.35.0 maps to v1.35
+	if got := k8sCeiling(versions, sampleGoMod); got != 37 {
+		t.Errorf("k8sCeiling() with go.mod = %d, want 37", got)
+	}
+	if got := k8sCeiling(nil, ""); got != 0 {
+		t.Errorf("k8sCeiling() without known versions = %d, want 0", got)
+	}
+}
+
+func TestCheckK8sVersionWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		tested  []string
+		wantErr bool
+	}{
+		{name: "match", tested: []string{"v1.35"}},
+		{name: "partial overlap", tested: []string{"v1.33", "v1.34", "v1.35"}},
+		{name: "entirely missing", tested: []string{"v1.32", "v1.33"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkK8sVersionWindow(tt.tested, "v1.35")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkK8sVersionWindow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAddReleaseFailOnK8sMismatch(t *testing.T) {
+	useFakeTransport(t, sampleGoMod)
+	root := newTestRepo(t)
+	opts := AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.32,v1.33", CheckK8sWindow: true}
+
+	if _, err := addRelease(opts); err != nil {
+		t.Fatalf("addRelease() with CheckK8sWindow error = %v, want a warning only", err)
+	}
+
+	opts.Tag = "v0.16.0"
+	opts.FailOnK8sMismatch = true
+	_, err := addRelease(opts)
+	if err == nil || !strings.Contains(err.Error(), "v1.35") {
+		t.Errorf("addRelease() error = %v, want a mismatch with v1.35", err)
+	}
+}
+
+func TestDiffK8sVersions(t *testing.T) {
+	tests := []struct {
+		name          string
+		previous      []string
+		next          []string
+		added, remove []string
+	}{
+		{name: "additions", previous: []string{"v1.32"}, next: []string{"v1.34", "v1.32", "v1.33"}, added: []string{"v1.33", "v1.34"}},
+		{name: "removals", previous: []string{"v1.31", "v1.30", "v1.32"}, next: []string{"v1.32"}, remove: []string{"v1.30", "v1.31"}},
+		{name: "both", previous: []string{"v1.30", "v1.35"}, next: []string{"v1.36", "v1.35"}, added: []string{"v1.36"}, remove: []string{"v1.30"}},
+		{name: "no change with different normalization", previous: []string{"v1.33", "1.32"}, next: []string{"v1.32.0", "v1.33"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed := diffK8sVersions(tt.previous, tt.next)
+			if !reflect.DeepEqual(added, tt.added) || !reflect.DeepEqual(removed, tt.remove) {
+				t.Errorf("diffK8sVersions() = %v, %v, want %v, %v", added, removed, tt.added, tt.remove)
+			}
+		})
+	}
+}
+
+func TestSplitK8sVersions(t *testing.T) {
+	tests := []struct {
+		list string
+		want []string
+	}{
+		{list: "", want: nil},
+		{list: ",", want: nil},
+		{list: " , ", want: nil},
+		{list: "v1.32", want: []string{"v1.32"}},
+		{list: " v1.32, ,v1.33,", want: []string{"v1.32", "v1.33"}},
+	}
+	for _, tt := range tests {
+		if got := splitK8sVersions(tt.list); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitK8sVersions(%q) = %q, want %q", tt.list, got, tt.want)
+		}
+	}
+}
+
+func TestAddReleaseEmptyTestedK8sVersions(t *testing.T) {
+	useFakeTransport(t, sampleGoMod)
+	root := newTestRepo(t)
+	warnings := &warningList{}
+
+	changes, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: ",", Warnings: warnings})
+	if err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	if want := []string{"v1.35"}; !reflect.DeepEqual(changes.TestedK8sVersions, want) {
+		t.Errorf("tested k8s versions = %q, want %q discovered from go.mod", changes.TestedK8sVersions, want)
+	}
+	if len(warnings.list()) != 1 {
+		t.Errorf("warnings = %q, want the empty list reported", warnings.list())
+	}
+
+	if err := replaceRelease(Settings{}, root, "eso", "v0.15.0", ReplaceOptions{TestedK8sVersions: ","}); exitCode(err) != exitInvalid {
+		t.Errorf("replaceRelease() error = %v, want an empty list refused", err)
+	}
+}
@@ -0,0 +1,409 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/BurntSushi/toml"
+	"golang.org/x/mod/semver"
+)
+
+// defaultContentLayout is the documentation section of projects without a
+// ContentLayout, e.g. eso-docs
+const defaultContentLayout = "{{.Project}}-docs"
+
+// ContentLayoutData is the data a ContentLayout is executed with
+type ContentLayoutData struct {
+	// Project is the project, or the content alias when set
+	Project string
+}
+
+// contentLayout returns the documentation section of project, relative to
+// content/en and with forward slashes, from its ContentLayout. The layout
+// gets alias instead of project when set.
+func contentLayout(project string, alias string) (string, error) {
+	layout := projects[project].ContentLayout
+	if layout == "" {
+		layout = defaultContentLayout
+	}
+	tmpl, err := template.New("content-layout").Option("missingkey=error").Parse(layout)
+	if err != nil {
+		return "", invalidf("invalid content layout %q of %s: %w", layout, project, err)
+	}
+	name := project
+	if alias != "" {
+		name = alias
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, ContentLayoutData{Project: name}); err != nil {
+		return "", invalidf("invalid content layout %q of %s: %w", layout, project, err)
+	}
+	section := b.String()
+	if section == "" || section == "." || section == ".." || path.IsAbs(section) || path.Clean(section) != section || strings.HasPrefix(section, "../") {
+		return "", invalidf("content layout %q of %s must give a relative folder such as components/%s/docs, got %q", layout, project, name, section)
+	}
+	return section, nil
+}
+
+// docsSection returns the name of the documentation section of project,
+// e.g. eso-docs. validateProject has checked its layout.
+func (s Settings) docsSection(project string) string {
+	section, err := contentLayout(project, s.ContentAlias)
+	if err != nil {
+		panic(err)
+	}
+	return section
+}
+
+// docsDir returns the content folder holding the documentation of project
+func (s Settings) docsDir(root string, project string) string {
+	return filepath.Join(root, "content", "en", filepath.FromSlash(s.docsSection(project)))
+}
+
+// checkProjectIndex reports whether the project index at path exists. It
+// fails early, before anything is written, when it is not a regular file.
+func checkProjectIndex(root string, project string, path string) (bool, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !info.Mode().IsRegular() {
+		return false, invalidf("the index of %s at %s is not a regular file, expected the project's _index.md page", project, relativeToRoot(root, path))
+	}
+	return true, nil
+}
+
+// safeVersionDir returns the folder of version, a direct child of baseDir.
+// Versions resolving anywhere else (e.g. ../../etc) are refused, as they
+// would make the tool write or delete outside of the documentation.
+func safeVersionDir(baseDir string, version string) (string, error) {
+	dir := filepath.Join(baseDir, version)
+	rel, err := filepath.Rel(baseDir, dir)
+	if err != nil || rel == "." || rel == ".." || strings.ContainsRune(rel, filepath.Separator) {
+		return "", invalidf("version %q resolves outside of %s", version, baseDir)
+	}
+	return dir, nil
+}
+
+// latestLink is the symlink to the latest version folder of --symlink-latest
+const latestLink = "latest"
+
+// updateLatestLink points the latest symlink of baseDir to the folder of
+// version, with a relative target, replacing the previous link.
+// Anything else named latest is left alone.
+func updateLatestLink(baseDir string, version string) error {
+	link := filepath.Join(baseDir, latestLink)
+	info, err := os.Lstat(link)
+	switch {
+	case err == nil && info.Mode()&os.ModeSymlink == 0:
+		return fmt.Errorf("%s exists and is not a symlink", link)
+	case err == nil:
+		if err := os.Remove(link); err != nil {
+			return err
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+	return os.Symlink(version, link)
+}
+
+// urlPath joins elements into an absolute URL path. URLs always use forward
+// slashes, so elements derived from file paths (e.g. with filepath.Join)
+// are converted from the OS separator first.
+func urlPath(elem ...string) string {
+	parts := make([]string, len(elem))
+	for i, e := range elem {
+		parts[i] = filepath.ToSlash(e)
+	}
+	return path.Join(append([]string{"/"}, parts...)...)
+}
+
+// docsURL returns the URL of the documentation of a version of project
+func (s Settings) docsURL(project string, version string) string {
+	return urlPath(s.docsSection(project), version) + "/"
+}
+
+// renderLandingPage renders ReleaseLandingPageTemplate for a version of project
+func renderLandingPage(longName string, project string, version string) string {
+	return fmt.Sprintf(ReleaseLandingPageTemplate, tomlString(longName+" "+version+" Documentation"), tomlString(version), tomlString(versionSlug(version)), tomlString(project), tomlString(version), longName, version)
+}
+
+// LandingPageData is the data a custom landing page template is executed with
+type LandingPageData struct {
+	LongName string
+	Project  string
+	Version  string
+	Slug     string
+}
+
+// renderVersionLandingPage renders the landing page of a version of project
+// from the text/template in templateFile, or from ReleaseLandingPageTemplate
+// when templateFile is empty.
+func renderVersionLandingPage(templateFile string, longName string, project string, version string) (string, error) {
+	if templateFile == "" {
+		return renderLandingPage(longName, project, version), nil
+	}
+	tmpl, err := template.New(filepath.Base(templateFile)).Option("missingkey=error").ParseFiles(templateFile)
+	if err != nil {
+		return "", invalidf("invalid landing page template: %w", err)
+	}
+	var b strings.Builder
+	data := LandingPageData{LongName: longName, Project: project, Version: version, Slug: versionSlug(version)}
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", invalidf("invalid landing page template: %w", err)
+	}
+	return b.String(), nil
+}
+
+var unsafeSlugChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// versionSlug returns the URL-safe slug of a version landing page, so that
+// Hugo does not derive it from the title: v0.15 stays v0.15 while
+// v0.15.0+build.1 becomes v0.15.0-build.1.
+func versionSlug(version string) string {
+	return strings.Trim(unsafeSlugChars.ReplaceAllString(strings.ToLower(version), "-"), "-")
+}
+
+// renderRootIndex renders RootIndexTemplate for project
+func renderRootIndex(longName string, project string) string {
+	return fmt.Sprintf(RootIndexTemplate, tomlString(longName+" Documentation"), tomlString(longName+" Docs"), tomlString(project))
+}
+
+// checkTOMLFrontMatter fails when the page at path, about to be written,
+// starts with TOML front matter (+++) that does not parse or is not closed
+func checkTOMLFrontMatter(path string, content string) error {
+	if !strings.HasPrefix(content, "+++") {
+		return nil
+	}
+	delimiter, frontMatter, ok := splitFrontMatter(content)
+	if !ok || delimiter != "+++" {
+		return invalidf("the front matter of %s is not closed by +++", path)
+	}
+	var page map[string]any
+	if _, err := toml.Decode(frontMatter, &page); err != nil {
+		return invalidf("the front matter of %s is not valid TOML: %w", path, err)
+	}
+	return nil
+}
+
+// tomlString quotes s as a TOML basic string
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// cascadeParam is a key = "value" of the [cascade.params] front matter table
+type cascadeParam struct {
+	Key   string
+	Value string
+}
+
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// parseCascadeParams parses key=value pairs. Keys must be TOML bare keys
+// and cannot override the params managed by the tool.
+func parseCascadeParams(pairs []string) ([]cascadeParam, error) {
+	var params []cascadeParam
+	for _, pair := range pairs {
+		key, value, found := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !found || !bareKey.MatchString(key) {
+			return nil, invalidf("invalid cascade param %q, expected key=value with key made of letters, digits, '_' or '-'", pair)
+		}
+		switch key {
+		case "project", "project_version", "project_go_version":
+			return nil, invalidf("cascade param %q is managed by the tool and cannot be set", key)
+		}
+		params = append(params, cascadeParam{Key: key, Value: value})
+	}
+	return params, nil
+}
+
+var cascadeParamsTable = regexp.MustCompile(`^\[cascade\.params\]$`)
+
+// setCascadeParam sets key to the string value in the [cascade.params] table
+// of a TOML (+++) front matter, keeping the indentation of the table's keys.
+// Content without such a table is returned unchanged.
+func setCascadeParam(content string, key string, value string) string {
+	lines := strings.SplitAfter(content, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "+++" {
+		return content
+	}
+	keyLine := regexp.MustCompile(`^` + regexp.QuoteMeta(key) + `\s*=`)
+
+	table := -1
+	for i := 1; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "+++" {
+			if table == -1 {
+				return content
+			}
+			return insertCascadeParam(lines, table, i, key, value)
+		}
+		if table == -1 {
+			if cascadeParamsTable.MatchString(trimmed) {
+				table = i
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "[") {
+			return insertCascadeParam(lines, table, i, key, value)
+		}
+		if keyLine.MatchString(trimmed) {
+			indent := lines[i][:len(lines[i])-len(strings.TrimLeft(lines[i], " \t"))]
+			lines[i] = fmt.Sprintf("%s%s = %s\n", indent, key, tomlString(value))
+			return strings.Join(lines, "")
+		}
+	}
+	return content
+}
+
+// insertCascadeParam adds key = value at the end of the table spanning
+// lines (table, end), after its last non blank line.
+func insertCascadeParam(lines []string, table int, end int, key string, value string) string {
+	last := table
+	indent := ""
+	for i := table + 1; i < end; i++ {
+		if strings.TrimSpace(lines[i]) != "" {
+			last = i
+			indent = lines[i][:len(lines[i])-len(strings.TrimLeft(lines[i], " \t"))]
+		}
+	}
+	if last == table {
+		// empty table: indent like the table header
+		indent = lines[table][:len(lines[table])-len(strings.TrimLeft(lines[table], " \t"))]
+	}
+	out := append([]string{}, lines[:last+1]...)
+	out = append(out, fmt.Sprintf("%s%s = %s\n", indent, key, tomlString(value)))
+	out = append(out, lines[last+1:]...)
+	return strings.Join(out, "")
+}
+
+// usesCRLF reports whether Windows line endings are dominant in content
+func usesCRLF(content string) bool {
+	crlf := strings.Count(content, "\r\n")
+	return crlf > 0 && crlf >= strings.Count(content, "\n")-crlf
+}
+
+// normalizeMarkdown makes text end with exactly one newline and use a
+// single newline convention, CRLF when crlf is set and LF otherwise, so
+// that rewriting a page only shows the intended changes in diffs.
+func normalizeMarkdown(text string, crlf bool) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.TrimRight(text, " \t\r\n") + "\n"
+	if crlf {
+		text = strings.ReplaceAll(text, "\n", "\r\n")
+	}
+	return text
+}
+
+var weightLine = regexp.MustCompile(`^weight\s*=`)
+
+// versionFolders returns the distinct major.minor folders of versions,
+// newest first
+func versionFolders(versions []Version) []string {
+	seen := map[string]bool{}
+	var folders []string
+	for _, v := range versions {
+		tag := normalizeVersion(v.Tag)
+		if !semver.IsValid(tag) {
+			continue
+		}
+		mm := semver.MajorMinor(tag)
+		if !seen[mm] {
+			seen[mm] = true
+			folders = append(folders, mm)
+		}
+	}
+	semver.Sort(folders)
+	for i, j := 0, len(folders)-1; i < j; i, j = i+1, j-1 {
+		folders[i], folders[j] = folders[j], folders[i]
+	}
+	return folders
+}
+
+// recomputeWeights rewrites the front matter weight of each version landing
+// page so that the sidebar lists them by semver order: the newest version
+// gets weight 1, the next one 2, and so on.
+// Version folders without a landing page are skipped. It returns the landing
+// pages it changed.
+func (s Settings) recomputeWeights(baseDir string, versions *VersionsData) ([]string, error) {
+	var updatedPages []string
+	for i, folder := range versionFolders(versions.Versions) {
+		indexPath := filepath.Join(baseDir, folder, "_index.md")
+		content, err := os.ReadFile(indexPath)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		// Only the weight changes, the rest of the page is left as it is
+		updated := setFrontMatterWeight(string(content), i+1)
+		if updated == string(content) {
+			continue
+		}
+		if err := s.writeGeneratedFile(indexPath, []byte(updated)); err != nil {
+			return nil, fmt.Errorf("failed to update weight of %s: %w", indexPath, err)
+		}
+		updatedPages = append(updatedPages, indexPath)
+	}
+	return updatedPages, nil
+}
+
+// setFrontMatterWeight sets the top level weight of a TOML (+++) front matter.
+// The weight is added before the first table when missing.
+// Content without TOML front matter is returned unchanged, and the other
+// lines, along with the line endings, are kept as they are.
+func setFrontMatterWeight(content string, weight int) string {
+	lines := strings.SplitAfter(content, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "+++" {
+		return content
+	}
+	eol := "\n"
+	if strings.HasSuffix(lines[0], "\r\n") {
+		eol = "\r\n"
+	}
+	newLine := fmt.Sprintf("weight = %d%s", weight, eol)
+	for i := 1; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		switch {
+		case weightLine.MatchString(trimmed):
+			lines[i] = newLine
+			return strings.Join(lines, "")
+		case trimmed == "+++" || strings.HasPrefix(trimmed, "["):
+			// Insert before the first table (or the end of the front matter)
+			// to keep weight a top level key
+			out := append([]string{}, lines[:i]...)
+			out = append(out, newLine)
+			out = append(out, lines[i:]...)
+			return strings.Join(out, "")
+		}
+	}
+	return content
+}
@@ -0,0 +1,690 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestSetFrontMatterWeight(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "replace existing weight",
+			content: "+++\ntitle = \"a\"\nweight = 1\n\n[[cascade]]\ntype = \"docs\"\n+++\nbody\n",
+			want:    "+++\ntitle = \"a\"\nweight = 3\n\n[[cascade]]\ntype = \"docs\"\n+++\nbody\n",
+		},
+		{
+			name:    "insert before first table",
+			content: "+++\ntitle = \"a\"\n[[cascade]]\ntype = \"docs\"\n+++\n",
+			want:    "+++\ntitle = \"a\"\nweight = 3\n[[cascade]]\ntype = \"docs\"\n+++\n",
+		},
+		{
+			name:    "insert at end of front matter",
+			content: "+++\ntitle = \"a\"\n+++\n",
+			want:    "+++\ntitle = \"a\"\nweight = 3\n+++\n",
+		},
+		{
+			name:    "no front matter",
+			content: "# title\n",
+			want:    "# title\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := setFrontMatterWeight(tt.content, 3); got != tt.want {
+				t.Errorf("setFrontMatterWeight() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecomputeWeights(t *testing.T) {
+	baseDir := t.TempDir()
+	for _, folder := range []string{"v0.9", "v0.10", "v1.0"} {
+		if err := os.MkdirAll(filepath.Join(baseDir, folder), 0755); err != nil {
+			t.Fatal(err)
+		}
+		content := "+++\ntitle = \"" + folder + "\"\nweight = 1\n+++\n"
+		if err := os.WriteFile(filepath.Join(baseDir, folder, "_index.md"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	versions := &VersionsData{Versions: []Version{
+		{Tag: "v0.9.1"}, {Tag: "v1.0.0"}, {Tag: "v0.10.2"}, {Tag: "v0.10.1"}, {Tag: "v0.8.0"},
+	}}
+
+	if _, err := (Settings{}).recomputeWeights(baseDir, versions); err != nil {
+		t.Fatalf("recomputeWeights() error = %v", err)
+	}
+
+	want := map[string]string{"v1.0": "weight = 1", "v0.10": "weight = 2", "v0.9": "weight = 3"}
+	for folder, weight := range want {
+		content, err := os.ReadFile(filepath.Join(baseDir, folder, "_index.md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "+++\ntitle = \"" + folder + "\"\n" + weight + "\n+++\n"
+		if string(content) != expected {
+			t.Errorf("%s/_index.md = %q, want %q", folder, content, expected)
+		}
+	}
+}
+
+func TestRecomputeWeightsKeepsThePage(t *testing.T) {
+	baseDir := t.TempDir()
+	pages := map[string]string{
+		"v0.10": "+++\ntitle  = \"v0.10\"\nweight = 1\n+++\n\nSome   text\t\n\n\n",
+		"v0.9":  "+++\r\ntitle = \"v0.9\"\r\n[[cascade]]\r\n  type = \"docs\"\r\n+++\r\n",
+	}
+	for folder, content := range pages {
+		if err := os.MkdirAll(filepath.Join(baseDir, folder), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(baseDir, folder, "_index.md"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	versions := &VersionsData{Versions: []Version{{Tag: "v0.10.0"}, {Tag: "v0.9.0"}}}
+
+	updated, err := Settings{}.recomputeWeights(baseDir, versions)
+	if err != nil {
+		t.Fatalf("recomputeWeights() error = %v", err)
+	}
+	if len(updated) != 1 || updated[0] != filepath.Join(baseDir, "v0.9", "_index.md") {
+		t.Errorf("recomputeWeights() = %v, want only the v0.9 page updated", updated)
+	}
+	want := map[string]string{
+		"v0.10": pages["v0.10"],
+		"v0.9":  "+++\r\ntitle = \"v0.9\"\r\nweight = 2\r\n[[cascade]]\r\n  type = \"docs\"\r\n+++\r\n",
+	}
+	for folder, expected := range want {
+		content, err := os.ReadFile(filepath.Join(baseDir, folder, "_index.md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != expected {
+			t.Errorf("%s/_index.md = %q, want %q", folder, content, expected)
+		}
+	}
+}
+
+func TestRenderLandingPageUnknownLongName(t *testing.T) {
+	projects["cert-manager"] = ProjectDetails{}
+	t.Cleanup(func() { delete(projects, "cert-manager") })
+
+	got := renderLandingPage(projectLongName("cert-manager"), "cert-manager", "v1.2")
+	for _, want := range []string{
+		`title = "Cert Manager v1.2 Documentation"`,
+		`project = "cert-manager"`,
+		`project_version = "v1.2"`,
+		"Welcome to the Cert Manager v1.2 documentation.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("renderLandingPage() does not contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestProjectLongName(t *testing.T) {
+	if got := projectLongName("eso"); got != "External-Secrets Operator" {
+		t.Errorf("projectLongName(eso) = %q", got)
+	}
+	if got := projectLongName("my_project"); got != "My Project" {
+		t.Errorf("projectLongName(my_project) = %q", got)
+	}
+}
+
+func TestSetCascadeParam(t *testing.T) {
+	page := "+++\ntitle = \"a\"\n\n[[cascade]]\ntype = \"docs\"\n\n  [cascade.params]\n  project = \"eso\"\n  project_version = \"v0.15\"\n+++\n\nbody\n"
+	tests := []struct {
+		name    string
+		content string
+		key     string
+		value   string
+		want    string
+	}{
+		{
+			name:    "add",
+			content: page,
+			key:     "project_go_version",
+			value:   "1.23.0",
+			want:    "+++\ntitle = \"a\"\n\n[[cascade]]\ntype = \"docs\"\n\n  [cascade.params]\n  project = \"eso\"\n  project_version = \"v0.15\"\n  project_go_version = \"1.23.0\"\n+++\n\nbody\n",
+		},
+		{
+			name:    "replace",
+			content: page,
+			key:     "project",
+			value:   `say "hi"`,
+			want:    "+++\ntitle = \"a\"\n\n[[cascade]]\ntype = \"docs\"\n\n  [cascade.params]\n  project = \"say \\\"hi\\\"\"\n  project_version = \"v0.15\"\n+++\n\nbody\n",
+		},
+		{
+			name:    "no cascade params",
+			content: "+++\ntitle = \"a\"\n+++\n",
+			key:     "project",
+			value:   "eso",
+			want:    "+++\ntitle = \"a\"\n+++\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := setCascadeParam(tt.content, tt.key, tt.value); got != tt.want {
+				t.Errorf("setCascadeParam() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddReleaseGoVersionFrontMatter(t *testing.T) {
+	useFakeTransport(t, sampleGoMod)
+	root := newTestRepo(t)
+	indexPath := filepath.Join(root, "content", "en", "eso-docs", "unreleased", "_index.md")
+	if err := os.WriteFile(indexPath, []byte(renderLandingPage(projectLongName("eso"), "eso", "unreleased")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0"})
+	if err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(root, "content", "en", "eso-docs", "v0.15", "_index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "  project_go_version = \"1.23.0\"\n") {
+		t.Errorf("landing page does not contain the go version:\n%s", content)
+	}
+	if !strings.Contains(changes.Markdown(), "Go version: 1.23.0") {
+		t.Errorf("summary does not contain the go version:\n%s", changes.Markdown())
+	}
+}
+
+func TestParseCascadeParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		pairs   []string
+		want    []cascadeParam
+		wantErr bool
+	}{
+		{
+			name:  "valid",
+			pairs: []string{"support_status=supported", "note=a=b"},
+			want:  []cascadeParam{{Key: "support_status", Value: "supported"}, {Key: "note", Value: "a=b"}},
+		},
+		{name: "missing value separator", pairs: []string{"support_status"}, wantErr: true},
+		{name: "empty key", pairs: []string{"=x"}, wantErr: true},
+		{name: "key with spaces", pairs: []string{"support status=x"}, wantErr: true},
+		{name: "managed key", pairs: []string{"project_version=v1"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCascadeParams(tt.pairs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCascadeParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && exitCode(err) != exitInvalid {
+				t.Errorf("parseCascadeParams() exit code = %d, want %d", exitCode(err), exitInvalid)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCascadeParams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddReleaseCascadeParams(t *testing.T) {
+	root := newTestRepo(t)
+	indexPath := filepath.Join(root, "content", "en", "eso-docs", "unreleased", "_index.md")
+	if err := os.WriteFile(indexPath, []byte(renderLandingPage(projectLongName("eso"), "eso", "unreleased")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := addRelease(AddOptions{
+		Root:              root,
+		Project:           "eso",
+		Tag:               "v0.15.0",
+		TestedK8sVersions: "v1.33",
+		CascadeParams:     []string{"support_status=supported", `motto=say "hi"`},
+	})
+	if err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(root, "content", "en", "eso-docs", "v0.15", "_index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "  project_version = \"v0.15\"\n  support_status = \"supported\"\n  motto = \"say \\\"hi\\\"\"\n+++"
+	if !strings.Contains(string(content), want) {
+		t.Errorf("landing page does not contain %q:\n%s", want, content)
+	}
+}
+
+func TestNormalizeMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		crlf bool
+		want string
+	}{
+		{name: "missing newline", text: "+++\n+++\nbody", want: "+++\n+++\nbody\n"},
+		{name: "extra newlines", text: "+++\n+++\nbody\n\n \n", want: "+++\n+++\nbody\n"},
+		{name: "crlf", text: "+++\r\nweight = 1\n+++\r\n\r\n", crlf: true, want: "+++\r\nweight = 1\r\n+++\r\n"},
+		{name: "mixed to lf", text: "+++\r\n+++\n", want: "+++\n+++\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeMarkdown(tt.text, tt.crlf); got != tt.want {
+				t.Errorf("normalizeMarkdown() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddReleaseKeepsCRLF(t *testing.T) {
+	root := newTestRepo(t)
+	indexPath := filepath.Join(root, "content", "en", "eso-docs", "unreleased", "_index.md")
+	landing := strings.ReplaceAll(renderLandingPage(projectLongName("eso"), "eso", "unreleased"), "\n", "\r\n") + "\r\n"
+	if err := os.WriteFile(indexPath, []byte(landing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := addRelease(AddOptions{
+		Root:              root,
+		Project:           "eso",
+		Tag:               "v0.15.0",
+		TestedK8sVersions: "v1.33",
+		CascadeParams:     []string{"support_status=supported"},
+	})
+	if err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(root, "content", "en", "eso-docs", "v0.15", "_index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(content)
+	if strings.Count(text, "\n") != strings.Count(text, "\r\n") {
+		t.Errorf("landing page mixes newline conventions:\n%q", text)
+	}
+	if !strings.HasSuffix(text, "documentation.\r\n") {
+		t.Errorf("landing page does not end with a single CRLF:\n%q", text)
+	}
+	if !strings.Contains(text, "support_status = \"supported\"\r\n") {
+		t.Errorf("landing page does not contain the cascade param:\n%q", text)
+	}
+}
+
+func TestVersionSlug(t *testing.T) {
+	for version, want := range map[string]string{
+		"v0.15":           "v0.15",
+		"v0.15.0":         "v0.15.0",
+		"v0.15.0-rc.1":    "v0.15.0-rc.1",
+		"v0.15.0+build.1": "v0.15.0-build.1",
+		"Unreleased":      "unreleased",
+	} {
+		if got := versionSlug(version); got != want {
+			t.Errorf("versionSlug(%q) = %q, want %q", version, got, want)
+		}
+	}
+
+	page := renderLandingPage(projectLongName("eso"), "eso", "v0.15")
+	if !strings.Contains(page, "slug = \"v0.15\"\n") {
+		t.Errorf("landing page does not contain the slug:\n%s", page)
+	}
+}
+
+func TestContentAlias(t *testing.T) {
+	root := newTestRepo(t)
+	if err := os.Rename(filepath.Join(root, "content", "en", "eso-docs"), filepath.Join(root, "content", "en", "external-secrets-docs")); err != nil {
+		t.Fatal(err)
+	}
+	settings := Settings{ContentAlias: "external-secrets"}
+
+	if got, want := settings.docsURL("eso", "v0.15"), "/external-secrets-docs/v0.15/"; got != want {
+		t.Errorf("docsURL() = %q, want %q", got, want)
+	}
+	if _, err := addRelease(AddOptions{Settings: settings, Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33"}); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "content", "en", "external-secrets-docs", "v0.15", "guide", "page.md")); err != nil {
+		t.Errorf("content was not copied under the alias: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "content", "en", "eso-docs")); !os.IsNotExist(err) {
+		t.Errorf("eso-docs was created despite the alias: %v", err)
+	}
+	if err := regenerateIndexes(settings, root, "eso", false); err != nil {
+		t.Errorf("regenerateIndexes() error = %v", err)
+	}
+}
+
+func TestContentLayout(t *testing.T) {
+	root := newTestRepo(t)
+	original := projects["eso"]
+	t.Cleanup(func() { projects["eso"] = original })
+	details := original
+	details.ContentLayout = "components/{{.Project}}/docs"
+	projects["eso"] = details
+
+	custom := filepath.Join(root, "content", "en", "components", "eso", "docs")
+	if err := os.MkdirAll(filepath.Dir(custom), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(root, "content", "en", "eso-docs"), custom); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := (Settings{}).docsDir(root, "eso"); got != custom {
+		t.Errorf("docsDir() = %q, want %q", got, custom)
+	}
+	if got, want := (Settings{}).docsURL("eso", "v0.15"), "/components/eso/docs/v0.15/"; got != want {
+		t.Errorf("docsURL() = %q, want %q", got, want)
+	}
+	paths, err := resolvePaths(Settings{}, root, "eso", "v0.15.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paths.BaseDir != custom {
+		t.Errorf("BaseDir = %q, want %q", paths.BaseDir, custom)
+	}
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33"}); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(custom, "v0.15", "guide", "page.md")); err != nil {
+		t.Errorf("content was not copied under the layout: %v", err)
+	}
+
+	// The alias replaces the project in the layout too
+	alias := Settings{ContentAlias: "external-secrets"}
+	if got, want := alias.docsURL("eso", "v0.15"), "/components/external-secrets/docs/v0.15/"; got != want {
+		t.Errorf("docsURL() with alias = %q, want %q", got, want)
+	}
+}
+
+func TestContentLayoutInvalid(t *testing.T) {
+	original := projects["eso"]
+	t.Cleanup(func() { projects["eso"] = original })
+
+	for _, layout := range []string{"{{.Project", "{{.Name}}-docs", "/{{.Project}}", "../{{.Project}}", "docs/", "."} {
+		details := original
+		details.ContentLayout = layout
+		projects["eso"] = details
+		if err := validateProject("eso"); !errors.Is(err, ErrInvalid) {
+			t.Errorf("validateProject() with layout %q = %v, want ErrInvalid", layout, err)
+		}
+	}
+}
+
+func TestDocsURL(t *testing.T) {
+	if got, want := (Settings{}).docsURL("eso", "v0.15"), "/eso-docs/v0.15/"; got != want {
+		t.Errorf("docsURL() = %q, want %q", got, want)
+	}
+}
+
+func TestURLPathForwardSlashes(t *testing.T) {
+	tests := []struct {
+		elem []string
+		want string
+	}{
+		{elem: []string{"eso-docs", "v0.15"}, want: "/eso-docs/v0.15"},
+		{elem: []string{filepath.Join("eso-docs", "v0.15")}, want: "/eso-docs/v0.15"},
+		{elem: []string{filepath.Join("content", "en"), "eso-docs/", "/v0.15"}, want: "/content/en/eso-docs/v0.15"},
+		{elem: []string{}, want: "/"},
+	}
+	for _, tt := range tests {
+		got := urlPath(tt.elem...)
+		if got != tt.want || strings.Contains(got, `\`) {
+			t.Errorf("urlPath(%q) = %q, want %q", tt.elem, got, tt.want)
+		}
+	}
+	if got := (Settings{}).docsURL("eso", filepath.Base(filepath.Join("content", "en", "eso-docs", "v0.15"))); got != "/eso-docs/v0.15/" {
+		t.Errorf("docsURL() = %q, want forward slashes only", got)
+	}
+}
+
+func TestAddReleaseLandingTemplate(t *testing.T) {
+	root := newTestRepo(t)
+	templateFile := filepath.Join(t.TempDir(), "landing.md")
+	if err := os.WriteFile(templateFile, []byte("+++\ntitle = \"{{.LongName}} {{.Version}}\"\nslug = \"{{.Slug}}\"\n+++\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33", LandingTemplate: templateFile}); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(root, "content", "en", "eso-docs", "v0.15", "_index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "+++\ntitle = \"External-Secrets Operator v0.15\"\nslug = \"v0.15\"\n"; !strings.HasPrefix(string(got), want) {
+		t.Errorf("landing page =\n%s\nwant it rendered from the template:\n%s", got, want)
+	}
+}
+
+func TestAddReleaseBrokenLandingTemplate(t *testing.T) {
+	root := newTestRepo(t)
+	dataFile := filepath.Join(root, "data", "eso_versions.toml")
+	original, err := os.ReadFile(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	templateFile := filepath.Join(t.TempDir(), "landing.md")
+	if err := os.WriteFile(templateFile, []byte("+++\ntitle = \"{{.Undefined}}\"\n+++\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33", LandingTemplate: templateFile})
+	if err == nil || !strings.Contains(err.Error(), "Undefined") {
+		t.Fatalf("addRelease() error = %v, want the undefined field", err)
+	}
+	if got, err := os.ReadFile(dataFile); err != nil || string(got) != string(original) {
+		t.Errorf("data file changed by a failed run:\n%s", got)
+	}
+	if _, err := os.Stat(filepath.Join(root, "content", "en", "eso-docs", "v0.15")); !os.IsNotExist(err) {
+		t.Errorf("version directory created by a failed run: %v", err)
+	}
+}
+
+func TestSafeVersionDir(t *testing.T) {
+	baseDir := filepath.Join("content", "en", "eso-docs")
+	tests := []struct {
+		version string
+		wantErr bool
+	}{
+		{version: "v0.15"},
+		{version: "unreleased"},
+		{version: "", wantErr: true},
+		{version: "..", wantErr: true},
+		{version: "../../etc", wantErr: true},
+		{version: "v0.15/../../reloader-docs", wantErr: true},
+		{version: "v0.15/nested", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, err := safeVersionDir(baseDir, tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("safeVersionDir(%q) = %s, %v, wantErr %v", tt.version, got, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAddReleaseMaliciousVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		opts AddOptions
+	}{
+		{name: "tag", opts: AddOptions{Tag: "../../etc"}},
+		{name: "tag with a valid prefix", opts: AddOptions{Tag: "v0.15.0/../../../etc"}},
+		{name: "copy from", opts: AddOptions{Tag: "v0.15.0", CopyFrom: "../../../etc"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := newTestRepo(t)
+			before := listTree(t, root)
+			opts := tt.opts
+			opts.Root, opts.Project, opts.TestedK8sVersions = root, "eso", "v1.33"
+
+			if _, err := addRelease(opts); err == nil {
+				t.Fatal("addRelease() succeeded, want an error")
+			}
+			if after := listTree(t, root); !reflect.DeepEqual(after, before) {
+				t.Errorf("files after the failed run = %v, want %v", after, before)
+			}
+		})
+	}
+}
+
+// listTree returns the paths below root with their size
+func TestLandingPageLongNameEscaped(t *testing.T) {
+	longName := `The "Quoted" \ Operator`
+	for name, page := range map[string]string{
+		"landing page": renderLandingPage(longName, "eso", "v0.15"),
+		"root index":   renderRootIndex(longName, "eso"),
+	} {
+		if err := checkTOMLFrontMatter(name, page); err != nil {
+			t.Errorf("%s does not parse: %v\n%s", name, err, page)
+			continue
+		}
+		_, frontMatter, _ := splitFrontMatter(page)
+		var decoded struct {
+			Title string `toml:"title"`
+		}
+		if _, err := toml.Decode(frontMatter, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(decoded.Title, longName+" ") {
+			t.Errorf("%s title = %q, want it to start with %q", name, decoded.Title, longName)
+		}
+	}
+}
+
+func TestAddReleaseInvalidFrontMatter(t *testing.T) {
+	root := newTestRepo(t)
+	original := projects["eso"]
+	t.Cleanup(func() { projects["eso"] = original })
+	details := original
+	details.ProjectLongName = `The "Quoted" Operator`
+	projects["eso"] = details
+	templateFile := filepath.Join(t.TempDir(), "landing.md")
+	if err := os.WriteFile(templateFile, []byte("+++\ntitle = \"{{.LongName}}\"\n+++\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33", LandingTemplate: templateFile})
+	if !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), "not valid TOML") {
+		t.Fatalf("addRelease() error = %v, want invalid TOML front matter", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "content", "en", "eso-docs", "v0.15")); !os.IsNotExist(err) {
+		t.Errorf("version directory created by a failed run: %v", err)
+	}
+}
+
+func TestCheckTOMLFrontMatterUnclosed(t *testing.T) {
+	if err := checkTOMLFrontMatter("_index.md", "+++\ntitle = \"x\"\n"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("checkTOMLFrontMatter() = %v, want ErrInvalid", err)
+	}
+	if err := checkTOMLFrontMatter("_index.md", "---\ntitle: x\n---\n"); err != nil {
+		t.Errorf("checkTOMLFrontMatter() on YAML = %v, want nil", err)
+	}
+}
+
+func listTree(t *testing.T, root string) map[string]int64 {
+	t.Helper()
+	tree := map[string]int64{}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		tree[path] = info.Size()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+func TestAddReleaseSymlinkLatest(t *testing.T) {
+	root := newTestRepo(t)
+	link := filepath.Join(root, "content", "en", "eso-docs", latestLink)
+
+	for _, tt := range []struct{ tag, want string }{{"v0.15.0", "v0.15"}, {"v0.16.0", "v0.16"}} {
+		if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: tt.tag, TestedK8sVersions: "v1.33", SymlinkLatest: true}); err != nil {
+			t.Fatalf("addRelease(%s) error = %v", tt.tag, err)
+		}
+		target, err := os.Readlink(link)
+		if err != nil {
+			t.Fatalf("latest symlink after %s: %v", tt.tag, err)
+		}
+		if target != tt.want {
+			t.Errorf("latest symlink after %s points to %s, want %s", tt.tag, target, tt.want)
+		}
+		if _, err := os.Stat(filepath.Join(link, "_index.md")); err != nil {
+			t.Errorf("latest symlink does not resolve to the version folder: %v", err)
+		}
+	}
+}
+
+func TestAddReleaseSymlinkLatestKeepsFolder(t *testing.T) {
+	root := newTestRepo(t)
+	folder := filepath.Join(root, "content", "en", "eso-docs", latestLink)
+	if err := os.Mkdir(folder, 0755); err != nil {
+		t.Fatal(err)
+	}
+	warnings := &warningList{}
+
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33", SymlinkLatest: true, Warnings: warnings}); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	if info, err := os.Lstat(folder); err != nil || !info.IsDir() {
+		t.Errorf("latest folder was replaced: %v", err)
+	}
+	if got := warnings.list(); len(got) != 1 || !strings.Contains(got[0], "not a symlink") {
+		t.Errorf("warnings = %q, want the latest folder to be reported", got)
+	}
+}
+
+func TestAddReleaseProjectIndexNotAFile(t *testing.T) {
+	root := newTestRepo(t)
+	rootIndex := filepath.Join(root, "content", "en", "eso-docs", "_index.md")
+	if err := os.Remove(rootIndex); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(rootIndex, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33"})
+	if !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), "content/en/eso-docs/_index.md is not a regular file") {
+		t.Fatalf("addRelease() error = %v, want the index location", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "content", "en", "eso-docs", "v0.15")); !os.IsNotExist(err) {
+		t.Errorf("version directory created despite the invalid index: %v", err)
+	}
+}
+
+func TestAddReleaseProjectIndexMissingWarns(t *testing.T) {
+	root := newTestRepo(t)
+	if err := os.Remove(filepath.Join(root, "content", "en", "eso-docs", "_index.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	warnings := &warningList{}
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33", NoIndexUpdate: true, Warnings: warnings}); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	if got := warnings.list(); len(got) != 1 || !strings.Contains(got[0], "expected at content/en/eso-docs/_index.md") {
+		t.Errorf("warnings = %v, want the expected index location", got)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+// redirectLayout is the layout of the project root indexes, which link the
+// latest and beta versions from the data files
+var redirectLayout = filepath.Join("..", "..", "layouts", "redirect", "baseof.html")
+
+// hugoStrings provides the strings namespace of Hugo templates
+type hugoStrings struct{}
+
+func (hugoStrings) TrimPrefix(prefix, s string) string { return strings.TrimPrefix(s, prefix) }
+
+// renderRedirectLayout renders the redirect layout for the root index of
+// project, with the data files of root, as Hugo does. The few Hugo
+// functions it uses are provided by their Go equivalents.
+func renderRedirectLayout(t *testing.T, root string, project string) string {
+	t.Helper()
+	data := map[string]any{}
+	for _, name := range []string{"eso", "reloader"} {
+		var versions map[string]any
+		if _, err := toml.DecodeFile((Settings{}).dataFilePath(root, name), &versions); err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+		data[name+"_versions"] = versions
+	}
+	site := map[string]any{"Data": data, "Language": map[string]any{"Lang": "en"}}
+	funcs := template.FuncMap{
+		"site":    func() map[string]any { return site },
+		"split":   strings.Split,
+		"in":      strings.Contains,
+		"strings": func() hugoStrings { return hugoStrings{} },
+	}
+	tmpl, err := template.New(filepath.Base(redirectLayout)).Funcs(funcs).ParseFiles(redirectLayout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := map[string]any{"Params": map[string]any{"project": project}, "Site": site}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, page); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func TestRedirectLayoutBeta(t *testing.T) {
+	tests := []struct {
+		name     string
+		project  string
+		versions string
+		wantURL  string
+		wantBeta string
+	}{
+		{
+			name:     "no prerelease",
+			project:  "eso",
+			versions: "[[versions]]\n  tag = \"v0.15.0\"\n  latest = true\n",
+			wantURL:  "/eso-docs/v0.15/",
+		},
+		{
+			name:     "prerelease before the latest",
+			project:  "eso",
+			versions: "[[versions]]\n  tag = \"v0.16.0-rc.2\"\n\n[[versions]]\n  tag = \"v0.16.0-rc.1\"\n\n[[versions]]\n  tag = \"v0.15.0\"\n  latest = true\n",
+			wantURL:  "/eso-docs/v0.15/",
+			wantBeta: `The v0.16.0-rc.2 pre-release is available in the <a href="/eso-docs/v0.16/">beta documentation</a>`,
+		},
+		{
+			name:     "build metadata is not a prerelease",
+			project:  "eso",
+			versions: "[[versions]]\n  tag = \"v0.16.0+build-1\"\n\n[[versions]]\n  tag = \"v0.15.0\"\n  latest = true\n",
+			wantURL:  "/eso-docs/v0.15/",
+		},
+		{
+			name:     "prerelease after the latest",
+			project:  "eso",
+			versions: "[[versions]]\n  tag = \"v0.15.0\"\n  latest = true\n\n[[versions]]\n  tag = \"v0.15.0-rc.1\"\n",
+			wantURL:  "/eso-docs/v0.15/",
+		},
+		{
+			name:     "reloader",
+			project:  "reloader",
+			versions: "[[versions]]\n  tag = \"v1.1.0-beta.1\"\n\n[[versions]]\n  tag = \"v1.0.0\"\n  latest = true\n",
+			wantURL:  "/reloader-docs/v1.0/",
+			wantBeta: `The v1.1.0-beta.1 pre-release is available in the <a href="/reloader-docs/v1.1/">beta documentation</a>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, map[string]string{"data/" + tt.project + "_versions.toml": tt.versions})
+
+			html := renderRedirectLayout(t, root, tt.project)
+			if want := `<a href="` + tt.wantURL + `">`; !strings.Contains(html, want) {
+				t.Errorf("redirect does not contain %q:\n%s", want, html)
+			}
+			if tt.wantBeta == "" && strings.Contains(html, "beta documentation") {
+				t.Errorf("redirect links a beta:\n%s", html)
+			}
+			if tt.wantBeta != "" && !strings.Contains(html, tt.wantBeta) {
+				t.Errorf("redirect does not contain %q:\n%s", tt.wantBeta, html)
+			}
+		})
+	}
+}
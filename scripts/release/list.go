@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Output formats of list
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputTSV  = "tsv"
+)
+
+func handleList(settings Settings, project string, since string, output string) {
+	if project == "" {
+		fmt.Print("Missing project\n")
+		printReleaseUsage()
+		os.Exit(1)
+	}
+	if err := validateProject(project); err != nil {
+		exitWithError(err)
+	}
+
+	versions, err := readVersions(settings.dataFilePath("", project))
+	if err != nil {
+		exitWithError(err)
+	}
+
+	listed := versions.Versions
+	if since != "" {
+		sinceDate, err := time.Parse("2006-01-02", since)
+		if err != nil {
+			exitWithError(invalidf("Invalid --since date %s, expected YYYY-MM-DD", since))
+		}
+		var skipped []string
+		listed, skipped = releasedSince(listed, sinceDate)
+		for _, tag := range skipped {
+			logWarning("skipping %s, its release date is missing or invalid", tag)
+		}
+	}
+
+	switch output {
+	case "", outputText, outputJSON:
+		err = printVersions(os.Stdout, listed, output == outputJSON)
+	case outputTSV:
+		err = printVersionsTSV(os.Stdout, listed)
+	default:
+		err = invalidf("invalid --output %q, expected %s, %s or %s", output, outputText, outputJSON, outputTSV)
+	}
+	if err != nil {
+		exitWithError(err)
+	}
+}
+
+func handleShowLatest(settings Settings, project string, asJSON bool) {
+	if project == "" {
+		fmt.Print("Missing project\n")
+		printReleaseUsage()
+		os.Exit(1)
+	}
+	if err := validateProject(project); err != nil {
+		exitWithError(err)
+	}
+
+	dataFile := settings.dataFilePath("", project)
+	versions, err := readVersions(dataFile)
+	if err != nil {
+		exitWithError(err)
+	}
+	if err := printLatest(os.Stdout, versions.Versions, asJSON); err != nil {
+		exitWithError(fmt.Errorf("%w in %s", err, dataFile))
+	}
+}
+
+// printLatest outputs the tag of the latest version, or {"latest": tag}
+// as JSON. It fails with ErrNoLatest when no version is the latest.
+func printLatest(w io.Writer, versions []Version, asJSON bool) error {
+	idx, err := latestIndex(versions)
+	if err != nil {
+		return err
+	}
+	tag := versions[idx].Tag
+	if asJSON {
+		out, err := json.Marshal(map[string]string{"latest": tag})
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", out)
+		return err
+	}
+	_, err = fmt.Fprintln(w, tag)
+	return err
+}
+
+// releasedSince returns the versions released on or after since.
+// Versions without a parseable release date are excluded and their tags
+// returned separately.
+func releasedSince(versions []Version, since time.Time) (released []Version, skipped []string) {
+	for _, v := range versions {
+		date, err := time.Parse("2006-01-02", v.ReleaseDate)
+		if err != nil {
+			skipped = append(skipped, v.Tag)
+			continue
+		}
+		if !date.Before(since) {
+			released = append(released, v)
+		}
+	}
+	return released, skipped
+}
+
+// printVersions outputs versions, one per line, or as a JSON array
+// tsvField replaces the tabs and line breaks a TSV field cannot hold
+var tsvField = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+
+// printVersionsTSV outputs versions as tab separated values with a header
+// row, e.g. for a spreadsheet. Tested k8s versions are joined with ;.
+func printVersionsTSV(w io.Writer, versions []Version) error {
+	if _, err := fmt.Fprintln(w, "tag\tversion\trelease_date\tend_of_life\tlatest\ttested_k8s_versions"); err != nil {
+		return err
+	}
+	for _, v := range versions {
+		fields := []string{v.Tag, v.Version, v.ReleaseDate, v.EndOfLife, strconv.FormatBool(v.Latest), strings.Join(v.TestedK8sVersions, ";")}
+		for i, field := range fields {
+			fields[i] = tsvField.Replace(field)
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(fields, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printVersions(w io.Writer, versions []Version, asJSON bool) error {
+	if asJSON {
+		if versions == nil {
+			versions = []Version{}
+		}
+		out, err := json.MarshalIndent(versions, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", out)
+		return err
+	}
+	for _, v := range versions {
+		line := fmt.Sprintf("%s\t%s\t%s", v.Tag, v.ReleaseDate, strings.Join(v.TestedK8sVersions, ","))
+		if v.Latest {
+			line += "\tlatest"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReleasedSince(t *testing.T) {
+	versions := []Version{
+		{Tag: "v0.16.0", ReleaseDate: "2025-03-01"},
+		{Tag: "v0.15.0", ReleaseDate: "2025-01-01"},
+		{Tag: "v0.14.1", ReleaseDate: ""},
+		{Tag: "v0.14.0", ReleaseDate: "01/12/2024"},
+		{Tag: "v0.13.0", ReleaseDate: "2024-12-31"},
+	}
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	released, skipped := releasedSince(versions, since)
+
+	var tags []string
+	for _, v := range released {
+		tags = append(tags, v.Tag)
+	}
+	if want := []string{"v0.16.0", "v0.15.0"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("released = %v, want %v", tags, want)
+	}
+	if want := []string{"v0.14.1", "v0.14.0"}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("skipped = %v, want %v", skipped, want)
+	}
+}
+
+func TestPrintVersions(t *testing.T) {
+	versions := []Version{{Tag: "v0.15.0", Latest: true, ReleaseDate: "2025-01-01", TestedK8sVersions: []string{"v1.32", "v1.33"}}}
+
+	var human strings.Builder
+	if err := printVersions(&human, versions, false); err != nil {
+		t.Fatal(err)
+	}
+	if want := "v0.15.0\t2025-01-01\tv1.32,v1.33\tlatest\n"; human.String() != want {
+		t.Errorf("printVersions() = %q, want %q", human.String(), want)
+	}
+
+	var out strings.Builder
+	if err := printVersions(&out, versions, true); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []Version
+	if err := json.Unmarshal([]byte(out.String()), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, versions) {
+		t.Errorf("printVersions() JSON = %v, want %v", decoded, versions)
+	}
+}
+
+func TestPrintVersionsTSV(t *testing.T) {
+	versions := []Version{
+		{Tag: "v0.15.0", Latest: true, ReleaseDate: "2025-01-01", TestedK8sVersions: []string{"v1.32", "v1.33"}, Version: "v0.15\tLTS"},
+		{Tag: "v0.14.0", ReleaseDate: "2024-10-01", EndOfLife: "2025-04-01"},
+	}
+
+	var out strings.Builder
+	if err := printVersionsTSV(&out, versions); err != nil {
+		t.Fatal(err)
+	}
+	want := "tag\tversion\trelease_date\tend_of_life\tlatest\ttested_k8s_versions\n" +
+		"v0.15.0\tv0.15 LTS\t2025-01-01\t\ttrue\tv1.32;v1.33\n" +
+		"v0.14.0\t\t2024-10-01\t2025-04-01\tfalse\t\n"
+	if out.String() != want {
+		t.Errorf("printVersionsTSV() = %q, want %q", out.String(), want)
+	}
+}
+
+func TestPrintLatest(t *testing.T) {
+	root := newTestRepo(t)
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33"}); err != nil {
+		t.Fatal(err)
+	}
+	versions, err := readVersions(Settings{}.dataFilePath(root, "eso"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	if err := printLatest(&out, versions.Versions, false); err != nil {
+		t.Fatalf("printLatest() error = %v", err)
+	}
+	if out.String() != "v0.15.0\n" {
+		t.Errorf("printLatest() = %q, want v0.15.0", out.String())
+	}
+
+	out.Reset()
+	if err := printLatest(&out, versions.Versions, true); err != nil {
+		t.Fatalf("printLatest() as JSON error = %v", err)
+	}
+	var got map[string]string
+	if err := json.Unmarshal([]byte(out.String()), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out.String())
+	}
+	if !reflect.DeepEqual(got, map[string]string{"latest": "v0.15.0"}) {
+		t.Errorf("printLatest() as JSON = %v, want latest v0.15.0", got)
+	}
+}
+
+func TestPrintLatestMissing(t *testing.T) {
+	var out strings.Builder
+	err := printLatest(&out, []Version{{Tag: "v0.15.0"}, {Tag: "v0.14.0"}}, false)
+	if !errors.Is(err, ErrNoLatest) || exitCode(err) == 0 {
+		t.Errorf("printLatest() error = %v, want ErrNoLatest", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("printLatest() printed %q without a latest version", out.String())
+	}
+}
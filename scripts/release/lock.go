@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+// lockDataFile takes an advisory lock on dataFile by creating a ".lock"
+// sidecar file, so that concurrent runs (e.g. racing CI jobs) cannot both
+// read, modify and write it. It fails fast when the lock is already held.
+// The returned function releases the lock.
+func lockDataFile(dataFile string) (func(), error) {
+	lockFile := dataFile + ".lock"
+	f, err := os.OpenFile(lockFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if os.IsExist(err) {
+		return nil, fmt.Errorf("%s is locked by another run; if no other run is in progress, remove %s", dataFile, lockFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock %s: %w", dataFile, err)
+	}
+	fmt.Fprintf(f, "%d\n", os.Getpid())
+	f.Close()
+
+	return func() {
+		if err := os.Remove(lockFile); err != nil {
+			log.Printf("Failed to release lock %s: %v", lockFile, err)
+		}
+	}, nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAddReleaseRefusesLockedDataFile(t *testing.T) {
+	root := newTestRepo(t)
+	dataFile := filepath.Join(root, "data", "eso_versions.toml")
+	unlock, err := lockDataFile(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+
+	_, err = addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33"})
+	if err == nil || !strings.Contains(err.Error(), "locked by another run") {
+		t.Fatalf("addRelease() error = %v, want locked error", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "content", "en", "eso-docs", "v0.15")); !os.IsNotExist(err) {
+		t.Errorf("locked run created the version folder")
+	}
+}
+
+func TestLockDataFileReleased(t *testing.T) {
+	root := newTestRepo(t)
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33"}); err == nil || strings.Contains(err.Error(), "locked") {
+		t.Fatalf("second addRelease() error = %v, want a non lock error", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "data", "eso_versions.toml.lock")); !os.IsNotExist(err) {
+		t.Errorf("lock file was not released")
+	}
+}
+
+func TestRemoveReleaseNonSemverTagReleasesLock(t *testing.T) {
+	root := newTestRepo(t)
+	dataFile := filepath.Join(root, "data", "eso_versions.toml")
+	if err := (Settings{}).writeVersions(dataFile, &VersionsData{Versions: []Version{
+		{Tag: "v0.14.0", Latest: true, ReleaseDate: "2025-01-01"},
+		{Tag: "nightly", ReleaseDate: "2025-01-01"},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Checking whether another release uses the folder goes through every
+	// tag while the data file is locked
+	if _, err := removeRelease(Settings{}, root, "eso", "v0.14.0", false); err != nil {
+		t.Fatalf("removeRelease() error = %v", err)
+	}
+	if _, err := os.Stat(dataFile + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("lock file was not released")
+	}
+}
@@ -1,89 +1,328 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
-	"log"
+	"io/fs"
 	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
-	"github.com/BurntSushi/toml"
+	"golang.org/x/mod/modfile"
 	"golang.org/x/mod/semver"
 )
 
 const (
 	ReleaseLandingPageTemplate string = `+++
-title = "%s %s Documentation"
-linkTitle = "%s"
+title = %s
+linkTitle = %s
+slug = %s
 sidebar_root_for = "self"
 
 [[cascade]]
 type = "docs"
 
   [cascade.params]
-  project = "%s"
-  project_version = "%s"
+  project = %s
+  project_version = %s
 +++
 
 Welcome to the %s %s documentation.
 `
 )
 
-// Version contains the structure of data/*_versions.toml
+// RootIndexTemplate is the project documentation root, redirecting to
+// the latest version
+const RootIndexTemplate string = `+++
+title = %s
+linkTitle = %s
+type = "redirect"
+
+[[cascade]]
+type = "docs"
+
+[cascade.params]
+project = %s
++++
+`
+
+// Version contains the structure of data/*_versions.toml (or .yaml)
 type Version struct {
-	Tag               string   `toml:"tag"`
-	Latest            bool     `toml:"latest"`
-	ReleaseDate       string   `toml:"release_date"`
-	TestedK8sVersions []string `toml:"tested_k8s_versions"`
-	EndOfLife         string   `toml:"end_of_life"`
+	Tag               string   `toml:"tag" json:"tag" yaml:"tag"`
+	Latest            bool     `toml:"latest" json:"latest" yaml:"latest"`
+	ReleaseDate       string   `toml:"release_date" json:"release_date" yaml:"release_date"`
+	TestedK8sVersions []string `toml:"tested_k8s_versions" json:"tested_k8s_versions" yaml:"tested_k8s_versions"`
+	EndOfLife         string   `toml:"end_of_life" json:"end_of_life" yaml:"end_of_life"`
+	// Version is the human readable version shown instead of the tag when
+	// set, e.g. v0.15 for v0.15.3
+	Version string `toml:"version,omitempty" json:"version,omitempty" yaml:"version,omitempty"`
 }
 
 // VersionsData contains all the parsed versions of the project
 type VersionsData struct {
-	Versions []Version `toml:"versions"`
+	// ProjectLongName overrides the long name configured for the project
+	ProjectLongName string `toml:"project_long_name,omitempty" json:"project_long_name,omitempty" yaml:"project_long_name,omitempty"`
+	// DefaultEOLMonths sets the end of life of new versions this many
+	// months after their release, when not zero
+	DefaultEOLMonths int `toml:"default_eol_months,omitzero" json:"default_eol_months,omitempty" yaml:"default_eol_months,omitempty"`
+	// LatestLabel is what the site templates append to the latest
+	// version, defaultLatestLabel when empty
+	LatestLabel string    `toml:"latest_label,omitempty" json:"latest_label,omitempty" yaml:"latest_label,omitempty"`
+	Versions    []Version `toml:"versions" json:"versions" yaml:"versions"`
+}
+
+// defaultLatestLabel is appended to the latest version without LatestLabel
+const defaultLatestLabel = "(latest)"
+
+// latestLabel returns the label of the latest version
+func (d *VersionsData) latestLabel() string {
+	if d != nil && d.LatestLabel != "" {
+		return d.LatestLabel
+	}
+	return defaultLatestLabel
+}
+
+// stripLatestLabel removes any of labels ending the human readable
+// version, as the templates append the current label to the latest one.
+// Labels written by hand are matched whatever their case and spacing, e.g.
+// "v0.14 ( Latest )" or "v0.14(latest)", and repeated ones are all removed.
+func stripLatestLabel(version string, labels ...string) string {
+	version = strings.TrimSpace(version)
+	for {
+		stripped := version
+		for _, label := range labels {
+			if pattern := latestLabelPattern(label); pattern != nil {
+				stripped = pattern.ReplaceAllString(stripped, "")
+			}
+		}
+		if stripped == version {
+			return version
+		}
+		version = stripped
+	}
+}
+
+// latestLabelPattern matches label at the end of a version, ignoring case
+// and whitespace, or is nil for a blank label
+func latestLabelPattern(label string) *regexp.Regexp {
+	var parts []string
+	for _, r := range label {
+		if !unicode.IsSpace(r) {
+			parts = append(parts, regexp.QuoteMeta(string(r)))
+		}
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return regexp.MustCompile(`(?i)\s*` + strings.Join(parts, `\s*`) + `\s*$`)
+}
+
+// longName returns the project long name of the data file header, falling
+// back to the configured one
+func (d *VersionsData) longName(project string) string {
+	if d != nil && d.ProjectLongName != "" {
+		return d.ProjectLongName
+	}
+	return projectLongName(project)
+}
+
+// endOfLife returns the end of life of a version released on releaseDate
+// (YYYY-MM-DD) following DefaultEOLMonths, or an empty string without policy
+func (d *VersionsData) endOfLife(releaseDate string) (string, error) {
+	if d == nil || d.DefaultEOLMonths == 0 {
+		return "", nil
+	}
+	released, err := time.Parse("2006-01-02", releaseDate)
+	if err != nil {
+		return "", invalidf("invalid release date %q: %w", releaseDate, err)
+	}
+	return released.AddDate(0, d.DefaultEOLMonths, 0).Format("2006-01-02"), nil
 }
 
 // ProjectDetails contains data for processing
 type ProjectDetails struct {
 	GoModLocation   string
 	ProjectLongName string
+	// GoModPath is the go.mod to read within the repository, e.g.
+	// apis/go.mod, defaults to the root go.mod of GoModLocation
+	GoModPath string
+	// TagPrefix precedes the version in the git tags of the project, e.g.
+	// reloader/ for reloader/v0.5.0. It is part of the go.mod location,
+	// while folders, URLs and the data file use the bare version.
+	TagPrefix string
+	// GitHubRepo is the owner/name of the GitHub repository of the
+	// project, whose release notes may annotate the tested k8s versions
+	GitHubRepo string
+	// ContentLayout is the template of the documentation section of the
+	// project under content/en, which is also its URL, e.g.
+	// components/{{.Project}}/docs. It defaults to {{.Project}}-docs.
+	ContentLayout string
+}
+
+// stripTagPrefix returns the version of the git tag of project, accepting
+// tags without its TagPrefix
+func stripTagPrefix(project string, tag string) string {
+	return strings.TrimPrefix(strings.TrimSpace(tag), projects[project].TagPrefix)
+}
+
+// gitTag returns the git tag of the version of project
+func gitTag(project string, version string) string {
+	return projects[project].TagPrefix + version
+}
+
+// normalizeVersion adds the leading v semver expects when it is missing,
+// keeping the given precision: "0.15" becomes "v0.15".
+// Tags, paths and URLs are built from normalized versions while
+// comparisons go through compareVersions, for which v0.15 equals v0.15.0.
+func normalizeVersion(version string) string {
+	version = strings.TrimSpace(version)
+	if version != "" && version[0] >= '0' && version[0] <= '9' {
+		return "v" + version
+	}
+	return version
+}
+
+// compareVersions compares two normalized versions like semver.Compare.
+// Invalid versions sort before valid ones and only equal identical strings.
+func compareVersions(a, b string) int {
+	a, b = normalizeVersion(a), normalizeVersion(b)
+	if a == b {
+		return 0
+	}
+	if c := semver.Compare(a, b); c != 0 || semver.IsValid(a) {
+		return c
+	}
+	return strings.Compare(a, b)
 }
 
 // extractMajorMinor extracts major.minor from a semver tag
 // Example: "v0.15.3" -> "v0.15"
-func extractMajorMinor(tag string) string {
+func extractMajorMinor(tag string) (string, error) {
+	tag = normalizeVersion(tag)
 	if !semver.IsValid(tag) {
-		log.Fatalf("Invalid semver tag: %s", tag)
+		return "", invalidf("Invalid semver tag: %s", tag)
 	}
-	return semver.MajorMinor(tag)
+	return semver.MajorMinor(tag), nil
 }
 
 // isDirectoryUsedByOtherRelease checks if a major.minor directory is still used
-// by other releases in the versions list
+// by other releases in the versions list. Tags which are not semver use no
+// directory.
 func isDirectoryUsedByOtherRelease(majorMinor string, tagToRemove string, versions []Version) bool {
 	for _, v := range versions {
-		if v.Tag == tagToRemove {
+		if compareVersions(v.Tag, tagToRemove) == 0 {
 			continue // Skip the version we're removing
 		}
-		if extractMajorMinor(v.Tag) == majorMinor {
+		if semver.MajorMinor(normalizeVersion(v.Tag)) == majorMinor {
 			return true
 		}
 	}
 	return false
 }
 
+// dirIsEmpty reports whether the directory path has no entries.
+// A missing directory is considered empty.
+func dirIsEmpty(path string) (bool, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	_, err = f.Readdirnames(1)
+	if err == io.EOF {
+		return true, nil
+	}
+	return false, err
+}
+
+// findDuplicateTag returns the stored tag equal to tag once normalized
+// (e.g. v0.15 and v0.15.0), or an empty string when there is none.
+func findDuplicateTag(tag string, versions []Version) string {
+	for _, v := range versions {
+		if compareVersions(v.Tag, tag) == 0 {
+			return v.Tag
+		}
+	}
+	return ""
+}
+
+// checkReleaseDateOrder returns an error when releaseDate is earlier than
+// the release date of previous, usually a mistake. Dates which are empty or
+// not YYYY-MM-DD are not checked.
+func checkReleaseDateOrder(previous Version, releaseDate string) error {
+	previousDate, err := time.Parse("2006-01-02", previous.ReleaseDate)
+	if err != nil {
+		return nil
+	}
+	date, err := time.Parse("2006-01-02", releaseDate)
+	if err != nil {
+		return nil
+	}
+	if date.Before(previousDate) {
+		return invalidf("release date %s is earlier than %s of the current latest %s", releaseDate, previous.ReleaseDate, previous.Tag)
+	}
+	return nil
+}
+
+// copyContent copies the documentation of a version, replaced in tests
+var copyContent = CopyDirWithOptions
+
 var (
 	projects = map[string]ProjectDetails{
-		"eso":      {GoModLocation: "https://raw.githubusercontent.com/external-secrets/external-secrets/%s/go.mod", ProjectLongName: "External-Secrets Operator"},
-		"reloader": {GoModLocation: "https://raw.githubusercontent.com/external-secrets/reloader/%s/go.mod", ProjectLongName: "Reloader Operator"},
+		"eso":      {GoModLocation: "https://raw.githubusercontent.com/external-secrets/external-secrets/%s/go.mod", ProjectLongName: "External-Secrets Operator", GitHubRepo: "external-secrets/external-secrets"},
+		"reloader": {GoModLocation: "https://raw.githubusercontent.com/external-secrets/reloader/%s/go.mod", ProjectLongName: "Reloader Operator", GitHubRepo: "external-secrets/reloader"},
 	}
 )
 
+// projectNames returns the configured projects, sorted
+func projectNames() []string {
+	names := make([]string, 0, len(projects))
+	for name := range projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateProject ensures project is configured
+func validateProject(project string) error {
+	if _, ok := projects[project]; !ok {
+		return invalidf("project must be one of '%s', got: %s", strings.Join(projectNames(), "', '"), project)
+	}
+	_, err := contentLayout(project, "")
+	return err
+}
+
+// projectLongName returns the configured long name of project, or the
+// title-cased project name when none is configured (e.g. "cert-manager"
+// becomes "Cert Manager").
+func projectLongName(project string) string {
+	if name := projects[project].ProjectLongName; name != "" {
+		return name
+	}
+	words := strings.FieldsFunc(project, func(r rune) bool { return r == '-' || r == '_' || r == ' ' })
+	for i, w := range words {
+		r, size := utf8.DecodeRuneInString(w)
+		words[i] = string(unicode.ToUpper(r)) + w[size:]
+	}
+	return strings.Join(words, " ")
+}
+
 func main() {
 	if len(os.Args) < 2 {
 		printReleaseUsage()
@@ -95,8 +334,25 @@ func main() {
 
 func printReleaseUsage() {
 	fmt.Println("Usage:")
-	fmt.Println("  release add --project <eso|reloader> --tag <version> [--release-date YYYY-MM-DD] [--tested-k8s-versions v1.26,v1.27]")
-	fmt.Println("  release delete --project <eso|reloader> --tag <version>")
+	fmt.Println("  release add --project <eso|reloader> --tag <version>|--version-from-git [--release-date YYYY-MM-DD] [--tested-k8s-versions v1.26,v1.27 | --k8s-from-release-notes] [--copy-from <unreleased|version>] [--copy-exclude-dir <dir>]... [--strip-drafts] [--manifest] [--archive-zip path] [--summary-file summary.md] [--print-plan] [--dry-run [--diff]] [--report-module <module>]... [--go-mod-url <url>]... [--cache-dir path] [--cache-ttl 1h | --no-cache] [--retry-max N] [--retry-base-delay 1s] [--retry-max-delay 30s] [--skip-unchanged] [--emit-json] [--repair] [--deref-symlinks] [--quiet] [--json] [--cascade-param key=value]... [--no-copy] [--require-content] [--strict-dates] [--landing-template file] [--display-version <version> | --canonical-version] [--latest-label label] [--no-demote | --beta] [--max-versions N [--prune-content]] [--no-index-update] [--symlink-latest] [--force] [--data-format toml|yaml] [--compact] [--file-mode 0644] [--dir-mode 0755] [--min-k8s-minor N] [--max-k8s-minor N] [--artifacts-dir path] [--check-k8s-window] [--fail-on-k8s-mismatch] [--content-alias name] [--git-commit [--git-allow-dirty]] [--post-hook \"cmd arg...\"] [--backup [--backup-cleanup]]")
+	fmt.Println("  release delete --project <eso|reloader> --tag <version> [--keep-content] [--backup [--backup-cleanup]]")
+	fmt.Println("  release rename --project <eso|reloader> --from <version> --to <version> [--backup [--backup-cleanup]]")
+	fmt.Println("  release replace --project <eso|reloader> --tag <version> [--release-date YYYY-MM-DD] [--tested-k8s-versions v1.26,v1.27] [--end-of-life YYYY-MM-DD] [--backup [--backup-cleanup]]")
+	fmt.Println("  release set-eol --project <eso|reloader> --tag <version> --end-of-life YYYY-MM-DD [--backup [--backup-cleanup]]")
+	fmt.Println("  release validate --project <eso|reloader> [--repair] [--backup [--backup-cleanup]] [-]")
+	fmt.Println("  release list --project <eso|reloader> [--since YYYY-MM-DD] [--json | --output text|json|tsv]")
+	fmt.Println("  release show-latest --project <eso|reloader> [--json]")
+	fmt.Println("  release print-paths --project <eso|reloader> --tag <version> [--json]")
+	fmt.Println("  release compare --project <eso|reloader> --from <version> --to <version> [--json]")
+	fmt.Println("  release list-eol --project <eso|reloader> [--expiring-days N] [--json]")
+	fmt.Println("  release fetch-only --project <eso|reloader> --tag <version> [--raw-base-url url] [--go-mod-url <url>]... [--cache-dir path] [--cache-ttl 1h | --no-cache] [--retry-max N] [--retry-base-delay 1s] [--retry-max-delay 30s] [--json]")
+	fmt.Println("  release oldest-supported --project <eso|reloader> [--json]")
+	fmt.Println("  release regenerate-indexes --project <eso|reloader> [--root-index]")
+	fmt.Println("  release canonicalize --project <eso|reloader> [--normalize-version-strings] [--data-format toml|yaml] [--compact] [--backup [--backup-cleanup]]")
+	fmt.Println("  release normalize-version-strings --project <eso|reloader> [--data-format toml|yaml] [--compact] [--backup [--backup-cleanup]]")
+	fmt.Println("  release watch --project <eso|reloader>")
+	fmt.Println("  release set-tested-k8s-versions --project <eso|reloader|all> --tested-k8s-versions v1.26,v1.27 [--min-k8s-minor N] [--max-k8s-minor N] [--backup [--backup-cleanup]]")
+	fmt.Println("Flags not given default to their " + flagEnvPrefix + "<FLAG> environment variable, e.g. " + flagEnvName("tested-k8s-versions") + ".")
 }
 
 func handleReleaseCommand() {
@@ -106,37 +362,495 @@ func handleReleaseCommand() {
 	releaseFlags := flag.NewFlagSet("release", flag.ExitOnError)
 	project := releaseFlags.String("project", "", "Project name (eso or reloader)")
 	tag := releaseFlags.String("tag", "", "Version tag (e.g., v0.15.3)")
+	versionFromGit := releaseFlags.Bool("version-from-git", false, "Without --tag, use the git tag of the checked out commit, or GITHUB_REF_NAME")
 	releaseDate := releaseFlags.String("release-date", "", "Release date (YYYY-MM-DD format, defaults to today)")
 	testedK8sVersions := releaseFlags.String("tested-k8s-versions", "", "Comma separated list of tested k8s version (e.g. v1.35,v1.36) for the release. Auto-discovered from go.mod if not provided.")
+	endOfLife := releaseFlags.String("end-of-life", "", "End of life date of the version (YYYY-MM-DD)")
+	copyFrom := releaseFlags.String("copy-from", "unreleased", "Content folder to seed the new version from: 'unreleased' or an existing version (e.g. v0.15)")
+	printPlan := releaseFlags.Bool("print-plan", false, "Print the ordered steps of the release as JSON before executing them")
+	dryRun := releaseFlags.Bool("dry-run", false, "Validate the inputs without changing anything on disk")
+	diff := releaseFlags.Bool("diff", false, "With --dry-run, print the unified diff of the data file and root index changes")
+	skipUnchanged := releaseFlags.Bool("skip-unchanged", false, "Do not rewrite version files having the same size and modification time as their source")
+	rawBaseURL := releaseFlags.String("raw-base-url", os.Getenv(rawBaseURLEnv), "Base URL replacing https://raw.githubusercontent.com to fetch go.mod files, e.g. an internal mirror (env "+rawBaseURLEnv+")")
+	repair := releaseFlags.Bool("repair", false, "Fix the data file inconsistencies that can be fixed (e.g. several latest versions) instead of failing")
+	derefSymlinks := releaseFlags.Bool("deref-symlinks", false, "Copy the files and directories symlinks point to instead of recreating the symlinks")
+	var cascadeParams stringList
+	releaseFlags.Var(&cascadeParams, "cascade-param", "Extra key=value cascade param of the version landing page (repeatable, e.g. support_status=supported)")
+	minK8sMinor := releaseFlags.Int("min-k8s-minor", defaultMinK8sMinor, "Oldest kubernetes minor accepted in the tested k8s versions (e.g. 16 for v1.16)")
+	maxK8sMinor := releaseFlags.Int("max-k8s-minor", 0, "Newest kubernetes minor accepted in the tested k8s versions, defaults to 2 minors after the newest known one")
+	noIndexUpdate := releaseFlags.Bool("no-index-update", false, "Never write the project root _index.md, e.g. when it is managed with shortcodes")
+	noCopy := releaseFlags.Bool("no-copy", false, "Only update the data file, without copying content nor writing the version landing page")
+	displayVersion := releaseFlags.String("display-version", "", "Human readable version stored with the tag, e.g. v0.15 for v0.15.3")
+	noDemote := releaseFlags.Bool("no-demote", false, "Leave the previous latest version untouched, still latest, until the data file is reconciled (e.g. with validate --repair)")
+	latestLabel := releaseFlags.String("latest-label", "", "Label the site appends to the latest version instead of \""+defaultLatestLabel+"\", e.g. [current], stored in the data file")
+	canonicalVersion := releaseFlags.Bool("canonical-version", false, "Store the major.minor of the tag as its human readable version")
+	landingTemplate := releaseFlags.String("landing-template", "", "text/template file rendering the version landing page, with .LongName, .Project, .Version and .Slug")
+	symlinkLatest := releaseFlags.Bool("symlink-latest", false, "Point the latest symlink of the docs folder to the new version folder")
+	k8sFromReleaseNotes := releaseFlags.Bool("k8s-from-release-notes", false, "Without --tested-k8s-versions, read them from the \"tested-k8s: v1.35,v1.34\" line of the GitHub release notes (authenticated with "+githubTokenEnv+" when set), before falling back to the go.mod")
+	archiveZip := releaseFlags.String("archive-zip", "", "Also archive the content source into this zip file, e.g. a downloadable bundle of the version docs (with --no-copy, instead of copying it)")
+	beta := releaseFlags.Bool("beta", false, "Add a prerelease as the beta version, linked from the root index, keeping the latest version as is")
+	maxVersions := releaseFlags.Int("max-versions", 0, "After adding the version, remove the lowest versions beyond this count from the data file, never the latest")
+	pruneContent := releaseFlags.Bool("prune-content", false, "With --max-versions, also delete the content folders the pruned versions leave unused")
+	keepContent := releaseFlags.Bool("keep-content", false, "With delete, only remove the version from the data file and keep its content folder")
+	manifest := releaseFlags.Bool("manifest", false, "Write "+manifestFile+" with the SHA-256 of each file of the version folder")
+	stripDraftPages := releaseFlags.Bool("strip-drafts", false, "Remove the copied markdown pages whose front matter sets draft = true")
+	strictDates := releaseFlags.Bool("strict-dates", false, "Fail instead of warning when the release date is earlier than the current latest's")
+	requireContent := releaseFlags.Bool("require-content", false, "Fail instead of warning when the content source has no files")
+	checkK8sWindow := releaseFlags.Bool("check-k8s-window", false, "Warn when --tested-k8s-versions misses the k8s version of the release's client-go")
+	failOnK8sMismatch := releaseFlags.Bool("fail-on-k8s-mismatch", false, "Like --check-k8s-window, but fail instead of warning")
+	force := releaseFlags.Bool("force", false, "Copy over an existing non-empty version folder not used by another release")
+	quiet := releaseFlags.Bool("quiet", false, "Do not print progress information")
+	var reportModules stringList
+	var goModURLs stringList
+	var copyExcludeDirs stringList
+	releaseFlags.Var(&copyExcludeDirs, "copy-exclude-dir", "Directory of the content source, relative to it, not to copy (repeatable, e.g. examples)")
+	releaseFlags.Var(&goModURLs, "go-mod-url", "go.mod to fetch instead of the release's one (repeatable, the one requiring the highest client-go is used)")
+	releaseFlags.Var(&reportModules, "report-module", "Print the version of this module from the release's go.mod (repeatable, e.g. sigs.k8s.io/controller-runtime)")
+	from := releaseFlags.String("from", "", "Version tag to rename or to compare from (e.g., v0.15.0-rc.1)")
+	to := releaseFlags.String("to", "", "New version tag, or the one to compare to (e.g., v0.15.0)")
+	since := releaseFlags.String("since", "", "Only list versions released on or after this date (YYYY-MM-DD)")
+	expiringDays := releaseFlags.Int("expiring-days", defaultExpiringDays, "Days before its end of life a version is reported as expiring soon")
+	asJSON := releaseFlags.Bool("json", false, "Output as JSON")
+	output := releaseFlags.String("output", "", "Output format of list: text, json (like --json) or tsv")
+	normalizeStrings := releaseFlags.Bool("normalize-version-strings", false, "Also clean the human readable version of each version, rebuilding it from its tag unless it is the full or major.minor form of the tag")
+	rootIndex := releaseFlags.Bool("root-index", false, "Also regenerate the project root index")
+	gitCommit := releaseFlags.Bool("git-commit", false, "Commit the changes of the release, e.g. \"docs(eso): add v0.15\", with git")
+	gitAllowDirty := releaseFlags.Bool("git-allow-dirty", false, "Let --git-commit run while unrelated changes are staged, they are left out of the commit")
+	postHook := releaseFlags.String("post-hook", "", "Command run from the repository root after a successful add, e.g. \"hugo --minify\"")
+	summaryFile := releaseFlags.String("summary-file", "", "Write a markdown summary of the changes to this file (e.g. for a PR description)")
+
+	emitJSON := releaseFlags.Bool("emit-json", false, "Also write data/<project>_versions.json, kept in sync on every change once it exists")
+	artifactsDir := releaseFlags.String("artifacts-dir", "", "Write the plan, summary and JSON mirror artifacts to this directory, created if needed")
+	contentAlias := releaseFlags.String("content-alias", "", "Name replacing the project in the <project>-docs content folder and URLs, e.g. external-secrets for external-secrets-docs")
+	backup := releaseFlags.Bool("backup", false, "Copy the data file to <data file>.bak before changing it")
+	backupCleanup := releaseFlags.Bool("backup-cleanup", false, "Remove the --backup copy once the run succeeded")
+	fileMode, dirMode := defaultFileMode, defaultDirMode
+	releaseFlags.Var(octalMode{&fileMode}, "file-mode", "Octal permissions of the files the tool creates, e.g. 0664 (copied files keep the ones of their source)")
+	releaseFlags.Var(octalMode{&dirMode}, "dir-mode", "Octal permissions of the folders the tool creates, e.g. 0775")
+	compactTOML := releaseFlags.Bool("compact", false, "Write each version of the TOML data file as an inline table on its own line")
+	cacheDir := releaseFlags.String("cache-dir", "", "Directory caching the fetched go.mod files, private to the user (default a folder of the user cache directory)")
+	cacheTTL := releaseFlags.Duration("cache-ttl", defaultGoModCacheTTL, "How long a cached go.mod is used instead of fetching it again")
+	noCache := releaseFlags.Bool("no-cache", false, "Always fetch the go.mod, without reading or writing the cache")
+	retryMax := releaseFlags.Int("retry-max", defaultRetryMax, "How many times a failed fetch is retried, 0 to disable the retries")
+	retryBaseDelay := releaseFlags.Duration("retry-base-delay", defaultRetryBaseDelay, "Wait before the first retry, doubled for each next one")
+	retryMaxDelay := releaseFlags.Duration("retry-max-delay", defaultRetryMaxDelay, "Longest wait between two retries")
+	dataFormat := releaseFlags.String("data-format", "", "Format of the data files, toml or yaml (detected from the existing data file by default)")
 
 	releaseFlags.Parse(os.Args[2:])
+	if err := applyFlagEnv(releaseFlags); err != nil {
+		exitWithError(err)
+	}
+	setupColors(*quiet || *asJSON)
+	setupProgress(*quiet, *asJSON)
+	// Keep stdout for the JSON output
+	resultOut := io.Writer(os.Stdout)
+	if *asJSON {
+		resultOut = os.Stderr
+	}
+	settings := Settings{
+		DataFormat:     *dataFormat,
+		CompactTOML:    *compactTOML,
+		EmitJSON:       *emitJSON,
+		ArtifactsDir:   *artifactsDir,
+		ContentAlias:   *contentAlias,
+		Backup:         *backup,
+		BackupCleanup:  *backupCleanup,
+		FileMode:       fileMode,
+		DirMode:        dirMode,
+		CacheDir:       *cacheDir,
+		CacheTTL:       *cacheTTL,
+		NoCache:        *noCache,
+		RetryMax:       *retryMax,
+		RetryBaseDelay: *retryBaseDelay,
+		RetryMaxDelay:  *retryMaxDelay,
+		backups:        map[string]string{},
+	}
+	if err := settings.validate(); err != nil {
+		exitWithError(err)
+	}
+
+	if *versionFromGit && *tag == "" {
+		version, err := tagFromGit("", projects[*project].TagPrefix)
+		if err != nil {
+			exitWithError(err)
+		}
+		*tag = version
+	}
+	// Versions are given either bare or as the prefixed git tag
+	*tag, *from, *to = stripTagPrefix(*project, *tag), stripTagPrefix(*project, *from), stripTagPrefix(*project, *to)
 
 	switch action {
 	case "add":
-		handleAdd(*project, *tag, *releaseDate, *testedK8sVersions)
+		var planOutput, diffOutput io.Writer
+		if *printPlan {
+			planOutput = os.Stdout
+			if settings.ArtifactsDir != "" {
+				planFile, err := settings.createArtifact(planArtifact)
+				if err != nil {
+					exitWithError(fmt.Errorf("Failed to create the plan artifact: %w", err))
+				}
+				defer planFile.Close()
+				planOutput = planFile
+			}
+		}
+		if *diff {
+			if !*dryRun {
+				exitWithError(invalidf("--diff requires --dry-run"))
+			}
+			diffOutput = os.Stdout
+		}
+		opts := AddOptions{
+			Settings:            settings,
+			Project:             *project,
+			Tag:                 *tag,
+			ReleaseDate:         *releaseDate,
+			TestedK8sVersions:   *testedK8sVersions,
+			K8sFromReleaseNotes: *k8sFromReleaseNotes,
+			CopyFrom:            *copyFrom,
+			PlanOutput:          planOutput,
+			DryRun:              *dryRun,
+			DiffOutput:          diffOutput,
+			ReportModules:       reportModules,
+			ModulesOutput:       resultOut,
+			GoModURLs:           goModURLs,
+			CopyExcludeDirs:     copyExcludeDirs,
+			StripDrafts:         *stripDraftPages,
+			Manifest:            *manifest,
+			ArchiveZip:          *archiveZip,
+			SkipUnchanged:       *skipUnchanged,
+			RawBaseURL:          *rawBaseURL,
+			Repair:              *repair,
+			DerefSymlinks:       *derefSymlinks,
+			Quiet:               *quiet || *asJSON,
+			CascadeParams:       cascadeParams,
+			NoCopy:              *noCopy,
+			RequireContent:      *requireContent,
+			StrictDates:         *strictDates,
+			SymlinkLatest:       *symlinkLatest,
+			LandingTemplate:     *landingTemplate,
+			DisplayVersion:      *displayVersion,
+			LatestLabel:         *latestLabel,
+			NoDemote:            *noDemote,
+			Beta:                *beta,
+			CanonicalVersion:    *canonicalVersion,
+			NoIndexUpdate:       *noIndexUpdate,
+			Force:               *force,
+			CheckK8sWindow:      *checkK8sWindow,
+			FailOnK8sMismatch:   *failOnK8sMismatch,
+			MinK8sMinor:         *minK8sMinor,
+			MaxK8sMinor:         *maxK8sMinor,
+			MaxVersions:         *maxVersions,
+			PruneContent:        *pruneContent,
+			GitCommit:           *gitCommit,
+			GitAllowDirty:       *gitAllowDirty,
+		}
+		handleAdd(opts, *summaryFile, *postHook, *asJSON)
 	case "delete":
-		handleRemove(*project, *tag)
+		handleRemove(settings, *project, *tag, *keepContent)
+	case "rename":
+		handleRename(settings, *project, *from, *to)
+	case "replace":
+		handleReplace(settings, *project, *tag, ReplaceOptions{
+			ReleaseDate:       *releaseDate,
+			TestedK8sVersions: *testedK8sVersions,
+			EndOfLife:         *endOfLife,
+		})
+	case "set-eol":
+		handleSetEOL(settings, *project, *tag, *endOfLife)
+	case "validate":
+		handleValidate(settings, *project, *repair, releaseFlags.Args())
+	case "list":
+		listOutput := *output
+		if *asJSON && listOutput == "" {
+			listOutput = outputJSON
+		}
+		handleList(settings, *project, *since, listOutput)
+	case "show-latest":
+		handleShowLatest(settings, *project, *asJSON)
+	case "print-paths":
+		handlePrintPaths(settings, *project, *tag, *asJSON)
+	case "fetch-only":
+		handleFetchOnly(settings, *project, *tag, *rawBaseURL, goModURLs, *asJSON)
+	case "oldest-supported":
+		handleOldestSupported(settings, *project, *asJSON)
+	case "compare":
+		handleCompare(settings, *project, *from, *to, *asJSON)
+	case "list-eol":
+		handleListEOL(settings, *project, *expiringDays, *asJSON)
+	case "regenerate-indexes":
+		handleRegenerateIndexes(settings, *project, *rootIndex)
+	case "canonicalize":
+		handleCanonicalize(settings, *project, *normalizeStrings)
+	case "normalize-version-strings":
+		handleNormalizeVersionStrings(settings, *project)
+	case "watch":
+		handleWatch(settings, *project)
+	case "set-tested-k8s-versions":
+		handleSetTestedK8sVersions(settings, *project, *testedK8sVersions, *minK8sMinor, *maxK8sMinor)
 	default:
 		fmt.Printf("Unknown release action: %s\n", action)
 		printReleaseUsage()
 		os.Exit(1)
 	}
+
+	// Failed runs exit above, keeping their backups
+	if err := settings.removeBackups(); err != nil {
+		exitWithError(err)
+	}
 }
 
-func handleAdd(project string, tag string, releaseDate string, testedK8sVersions string) {
+func handleAdd(opts AddOptions, summaryFile string, postHook string, asJSON bool) {
 	// Validate inputs
-	if project == "" || tag == "" {
+	if opts.Project == "" || opts.Tag == "" {
 		fmt.Print("Missing project or tag\n")
 		printReleaseUsage()
 		os.Exit(1)
 	}
 
-	if project != "eso" && project != "reloader" {
-		log.Fatalf("project must be 'eso' or 'reloader', got: %s", project)
+	// Check before changing anything, the commit is made once all is done
+	if opts.GitCommit && !opts.GitAllowDirty && !opts.DryRun {
+		if err := checkStagedChanges(opts.Root); err != nil {
+			exitWithError(err)
+		}
+	}
+
+	warnings := &warningList{}
+	opts.Warnings = warnings
+	opts.Timings = &Timings{}
+	changes, err := addRelease(opts)
+	if err != nil {
+		warnings.print(os.Stderr)
+		exitWithError(err)
+	}
+	if opts.DryRun {
+		printAddResult(changes, opts, asJSON)
+		return
+	}
+
+	if summaryFile != "" {
+		summaryFile, err := opts.Settings.artifactPath(summaryFile)
+		if err != nil {
+			exitWithError(fmt.Errorf("Failed to write summary: %w", err))
+		}
+		if err := opts.Settings.writeGeneratedFile(summaryFile, []byte(changes.Markdown())); err != nil {
+			exitWithError(fmt.Errorf("Failed to write summary: %w", err))
+		}
+		printProgress("Wrote summary to %s\n", summaryFile)
+	}
+	if err := changes.writeGitHubOutput(opts.Settings); err != nil {
+		exitWithError(fmt.Errorf("Failed to write GitHub Actions outputs: %w", err))
+	}
+
+	if opts.GitCommit {
+		if err := gitCommit(opts.Root, changes); err != nil {
+			warnings.print(os.Stderr)
+			exitWithError(err)
+		}
+	}
+
+	if err := runPostHook(opts.Root, postHook, progressOut, os.Stderr); err != nil {
+		warnings.print(os.Stderr)
+		exitWithHookError(err)
+	}
+
+	// Only suggest the next steps once the commit and the hook succeeded
+	if !opts.Quiet {
+		majorMinor, err := extractMajorMinor(opts.Tag)
+		if err != nil {
+			exitWithError(err)
+		}
+		fmt.Println()
+		printStep("Release %s added successfully!", opts.Tag)
+		fmt.Printf("Documentation will be available at: %s\n", opts.Settings.docsURL(opts.Project, majorMinor))
+		fmt.Printf("Next steps:\n")
+		fmt.Printf("1. Review the changes\n")
+		if opts.GitCommit {
+			fmt.Printf("2. Push the commit\n")
+		} else {
+			fmt.Printf("2. Commit and push\n")
+		}
+	}
+	printAddResult(changes, opts, asJSON)
+}
+
+// addResult is the JSON output of the add action
+type addResult struct {
+	// Changes is nil on dry runs
+	Changes  *Changeset   `json:"changes"`
+	Warnings []string     `json:"warnings"`
+	Timings  *timingsJSON `json:"timings,omitempty"`
+}
+
+// printAddResult ends an add run with its warnings and timings, unless
+// quiet, or with the changes, warnings and timings as JSON
+func printAddResult(changes *Changeset, opts AddOptions, asJSON bool) {
+	if asJSON {
+		result := addResult{Changes: changes, Warnings: opts.Warnings.list(), Timings: opts.Timings.json()}
+		out, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			exitWithError(err)
+		}
+		fmt.Printf("%s\n", out)
+		return
+	}
+	if err := opts.Warnings.print(os.Stdout); err != nil {
+		exitWithError(err)
+	}
+	if !opts.Quiet {
+		if err := opts.Timings.print(os.Stdout); err != nil {
+			exitWithError(err)
+		}
+	}
+}
+
+// AddOptions contains the inputs of the add action
+type AddOptions struct {
+	// Root is the website checkout to operate on, defaults to the working directory
+	Root string
+	// Settings are the flags shared with the other actions
+	Settings          Settings
+	Project           string
+	Tag               string
+	ReleaseDate       string
+	TestedK8sVersions string
+	// CopyFrom is the content folder seeding the new version: "unreleased"
+	// (the default) or an existing version
+	CopyFrom string
+	// CopyExcludeDirs are directories of the content source, relative to
+	// it, which are not copied (e.g. generated examples)
+	CopyExcludeDirs []string
+	// Manifest writes manifest.json, the SHA-256 of each file, in the
+	// version folder
+	Manifest bool
+	// ArchiveZip, when set, is a zip file the content source is archived
+	// into, along with the copy or, with NoCopy, instead of it
+	ArchiveZip string
+	// StripDrafts removes the copied pages marked as draft, and the
+	// directories they leave empty
+	StripDrafts bool
+	// PlanOutput receives the JSON plan of the steps to execute, when set
+	PlanOutput io.Writer
+	// DryRun stops before changing anything on disk
+	DryRun bool
+	// DiffOutput receives, on dry runs, the unified diff of the data file
+	// and root index changes, when set
+	DiffOutput io.Writer
+	// SkipUnchanged does not rewrite content files identical to their source
+	SkipUnchanged bool
+	// Repair clears extra latest flags, keeping the highest version, instead
+	// of failing
+	Repair bool
+	// DerefSymlinks copies the content of symlinks instead of the links
+	DerefSymlinks bool
+	// CascadeParams are extra key=value cascade params of the landing page
+	CascadeParams []string
+	// CheckK8sWindow fetches the release's go.mod to warn when the tested
+	// k8s versions miss the one of its client-go, FailOnK8sMismatch makes
+	// it an error
+	CheckK8sWindow    bool
+	FailOnK8sMismatch bool
+	// Force copies over a non empty version folder that no release of the
+	// same major.minor uses
+	Force bool
+	// MinK8sMinor and MaxK8sMinor bound the minor of the tested k8s
+	// versions. A zero MaxK8sMinor derives the ceiling from the newest
+	// known k8s version.
+	MinK8sMinor int
+	MaxK8sMinor int
+	// SymlinkLatest points the latest symlink of the docs folder to the new
+	// version folder
+	SymlinkLatest bool
+	// NoIndexUpdate never writes the project root index
+	NoIndexUpdate bool
+	// NoCopy only updates the data file, the content being provided by
+	// another pipeline
+	NoCopy bool
+	// StrictDates fails, instead of warning, when the release date is
+	// earlier than the one of the current latest version
+	StrictDates bool
+	// RequireContent fails, instead of warning, when the content source
+	// has no files
+	RequireContent bool
+	// LandingTemplate is a text/template file rendering the landing page of
+	// the version instead of the one of the content source, when set
+	LandingTemplate string
+	// NoDemote leaves the previous latest version untouched, still marked
+	// as latest, e.g. during a staged migration
+	NoDemote bool
+	// Beta adds a prerelease without marking it as latest, the previous
+	// latest staying the stable one, for the beta link of the root index
+	Beta bool
+	// LatestLabel replaces the label the site appends to the latest
+	// version, stored in the data file header
+	LatestLabel string
+	// DisplayVersion is the human readable version stored with the tag,
+	// CanonicalVersion derives it as the major.minor of the tag
+	DisplayVersion   string
+	CanonicalVersion bool
+	// Quiet disables progress output
+	Quiet bool
+	// Warnings collects the warnings of the run, when set
+	Warnings *warningList
+	// Timings records the duration of the phases of the run, when set
+	Timings *Timings
+	// RawBaseURL replaces the scheme and host (e.g. an internal mirror) of
+	// the go.mod location
+	RawBaseURL string
+	// GoModURLs are the go.mod files to fetch instead of the release's one,
+	// for releases spanning several modules: the one requiring the highest
+	// client-go is used
+	GoModURLs []string
+	// GitCommit commits the changes of the run once it succeeded, failing
+	// beforehand when unrelated changes are staged unless GitAllowDirty
+	GitCommit     bool
+	GitAllowDirty bool
+	// Transport sends the requests of the run, e.g. through a proxy or to
+	// a stub in tests, httpClient sends them when nil
+	Transport http.RoundTripper
+	// ReportModules lists modules whose version in the release's go.mod is
+	// printed to ModulesOutput, when set
+	ReportModules []string
+	ModulesOutput io.Writer
+	// MaxVersions prunes the lowest versions beyond this count, never the
+	// latest, when set. PruneContent also deletes the folders they leave
+	// unused.
+	MaxVersions  int
+	PruneContent bool
+	// K8sFromReleaseNotes reads the tested k8s versions, when not given,
+	// from the tested-k8s annotation of the GitHub release notes before
+	// deriving them from the go.mod
+	K8sFromReleaseNotes bool
+}
+
+// addRelease adds a new release to the project data file and creates its
+// documentation folder from the unreleased content.
+// It returns a summary of the changes done on disk, nil in dry run.
+func addRelease(opts AddOptions) (_ *Changeset, err error) {
+	project := opts.Project
+	tag := normalizeVersion(stripTagPrefix(project, opts.Tag))
+	releaseDate := opts.ReleaseDate
+	testedK8sVersions := opts.TestedK8sVersions
+
+	if err := validateProject(project); err != nil {
+		return nil, err
 	}
 
 	if !semver.IsValid(tag) {
-		log.Fatalf("Invalid semver tag: %s. Use full semver like v0.15.0", tag)
+		return nil, invalidf("Invalid semver tag: %s. Use full semver like v0.15.0", tag)
+	}
+	if opts.MaxVersions < 0 {
+		return nil, invalidf("--max-versions must be positive, got %d", opts.MaxVersions)
+	}
+	if opts.PruneContent && opts.MaxVersions == 0 {
+		return nil, invalidf("--prune-content requires --max-versions")
+	}
+
+	displayVersion := opts.DisplayVersion
+	if opts.CanonicalVersion {
+		if displayVersion != "" {
+			return nil, invalidf("--display-version and --canonical-version are mutually exclusive")
+		}
+		displayVersion = semver.MajorMinor(tag)
 	}
 
 	// Set defaults for release date
@@ -144,287 +858,1032 @@ func handleAdd(project string, tag string, releaseDate string, testedK8sVersions
 		releaseDate = time.Now().Format("2006-01-02")
 	}
 
-	// Auto-discover k8s versions if not provided
-	if testedK8sVersions == "" {
-		log.Print("Did not receive the list of the tested k8s versions, will fetch the supported version from release's go.mod")
-		url := fmt.Sprintf(projects[project].GoModLocation, tag)
-		if body, err := fetchGoMod(url); err != nil {
-			log.Fatalf("failed to fetch from %s: %v", url, err)
+	// Fetch the release's go.mod when something needs it
+	var goMod string
+	goModURLs := opts.GoModURLs
+	if len(goModURLs) == 0 {
+		goModURL, err := resolveGoModURL(project, tag, opts.RawBaseURL)
+		if err != nil {
+			return nil, err
+		}
+		goModURLs = []string{goModURL}
+	}
+	goModURL := goModURLs[0]
+	checkK8sWindow := opts.CheckK8sWindow || opts.FailOnK8sMismatch
+	// A list without any version (e.g. ",") is no list, rather than [""]
+	if tested := splitK8sVersions(testedK8sVersions); len(tested) == 0 && testedK8sVersions != "" {
+		opts.Warnings.add("--tested-k8s-versions %q has no version, discovering them from the release's go.mod", testedK8sVersions)
+		testedK8sVersions = ""
+	} else {
+		testedK8sVersions = strings.Join(tested, ",")
+	}
+	client := httpClientFor(opts.Transport)
+	if testedK8sVersions == "" && opts.K8sFromReleaseNotes {
+		body, err := opts.Settings.fetchReleaseBody(client, project, tag)
+		if err != nil {
+			return nil, err
+		}
+		if tested, found := parseTestedK8sAnnotation(body); found && len(tested) > 0 {
+			testedK8sVersions = strings.Join(tested, ",")
+			printStep("Read the tested k8s versions %s from the release notes of %s", testedK8sVersions, tag)
 		} else {
-			clientGo, errParse := parseK8sClientGoVersion(string(body))
-			if errParse != nil {
-				log.Fatal(errParse)
+			printProgress("No tested-k8s annotation in the release notes of %s, falling back to its go.mod\n", tag)
+		}
+	}
+	if testedK8sVersions == "" || len(opts.ReportModules) > 0 || checkK8sWindow {
+		// An empty list (e.g. ",") was already reported
+		if testedK8sVersions == "" && opts.TestedK8sVersions == "" {
+			opts.Warnings.add("did not receive the list of the tested k8s versions, will fetch the supported version from the release's go.mod")
+		}
+		stop := opts.Timings.start(PhaseFetch)
+		goModURL, goMod, err = opts.Settings.fetchHighestClientGoMod(client, goModURLs)
+		if err != nil {
+			return nil, err
+		}
+		stop()
+	}
+
+	// Cross-check the supplied versions with the client-go of the release
+	if testedK8sVersions != "" && checkK8sWindow {
+		clientGo, err := parseK8sClientGoVersion(goMod)
+		if err != nil {
+			return nil, fmt.Errorf("cannot check the tested k8s versions of %s against %s: %w", tag, goModURL, err)
+		}
+		if err := checkK8sVersionWindow(splitK8sVersions(testedK8sVersions), convertClientGoToRealK8sVersion(clientGo)); err != nil {
+			if opts.FailOnK8sMismatch {
+				return nil, err
 			}
-			testedK8sVersions = convertClientGoToRealK8sVersion(clientGo)
+			opts.Warnings.add("%v", err)
+		}
+	}
+
+	// Auto-discover k8s versions if not provided
+	if testedK8sVersions == "" {
+		clientGo, err := parseK8sClientGoVersion(goMod)
+		if err != nil {
+			return nil, fmt.Errorf("cannot discover the tested k8s versions of %s from %s: %w; pass them with --tested-k8s-versions instead", tag, goModURL, err)
+		}
+		testedK8sVersions = convertClientGoToRealK8sVersion(clientGo)
+	}
+
+	// Go toolchain of the release, unknown when go.mod was not fetched
+	goVersion := parseGoVersion(goMod)
+
+	if len(opts.ReportModules) > 0 && opts.ModulesOutput != nil {
+		if err := reportModules(opts.ModulesOutput, goMod, opts.ReportModules); err != nil {
+			return nil, err
 		}
 	}
 
 	// Determine paths
-	baseDir := filepath.Join("content", "en", fmt.Sprintf("%s-docs", project))
-	dataFile := filepath.Join("data", fmt.Sprintf("%s_versions.toml", project))
+	baseDir := opts.Settings.docsDir(opts.Root, project)
+	dataFile := opts.Settings.dataFilePath(opts.Root, project)
 
 	// Check if data file exists
 	if _, err := os.Stat(dataFile); os.IsNotExist(err) {
-		log.Fatalf("Data file not found: %s", dataFile)
+		return nil, fmt.Errorf("%w: %s", ErrDataFileMissing, dataFile)
+	}
+	// Without --no-index-update a missing project index is bootstrapped
+	rootIndexPath := filepath.Join(baseDir, "_index.md")
+	indexExists, err := checkProjectIndex(opts.Root, project, rootIndexPath)
+	if err != nil {
+		return nil, err
+	}
+	if !indexExists && opts.NoIndexUpdate {
+		opts.Warnings.add("the index of %s is expected at %s, create it or drop --no-index-update to generate it", project, relativeToRoot(opts.Root, rootIndexPath))
+	}
+
+	// Prevent concurrent runs from overwriting each other
+	unlock, err := lockDataFile(dataFile)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	cascadeParams, err := parseCascadeParams(opts.CascadeParams)
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range opts.CopyExcludeDirs {
+		clean := filepath.Clean(dir)
+		if filepath.IsAbs(clean) || clean == "." || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+			return nil, invalidf("--copy-exclude-dir %q must be a directory below the content source", dir)
+		}
 	}
 
-	// Read existing versions
+	// Resolve and validate the content source before touching anything
+	sourceName, err := copySourceName(opts.CopyFrom)
+	if err != nil {
+		return nil, err
+	}
+	majorMinor, err := extractMajorMinor(tag)
+	if err != nil {
+		return nil, err
+	}
+	if sourceName == majorMinor {
+		return nil, invalidf("cannot copy %s onto itself, pick another --copy-from", sourceName)
+	}
+	sourceDir, err := safeVersionDir(baseDir, sourceName)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(sourceDir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("Content source not found: %s", sourceDir)
+	}
+	// A source without files would only give the new version its landing page
+	if !opts.NoCopy {
+		files, err := countFiles(osFS{}, sourceDir, opts.CopyExcludeDirs)
+		if err != nil {
+			return nil, err
+		}
+		if files == 0 {
+			if opts.RequireContent {
+				return nil, fmt.Errorf("content source %s has no files", sourceDir)
+			}
+			opts.Warnings.add("content source %s has no files, the new version will only have its landing page", sourceDir)
+		}
+	}
+
+	// Read existing versions. An empty data file means the project is
+	// bootstrapped: the new version is the first one.
+	stop := opts.Timings.start(PhaseRead)
 	versions, err := readVersions(dataFile)
+	stop()
+	firstVersion := errors.Is(err, ErrNoVersions)
+	if firstVersion {
+		err = nil
+	}
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
+	}
+
+	maxK8sMinor := opts.MaxK8sMinor
+	if maxK8sMinor == 0 {
+		maxK8sMinor = k8sCeiling(versions.Versions, goMod)
+	}
+	if err := validateTestedK8sVersions(splitK8sVersions(testedK8sVersions), opts.MinK8sMinor, maxK8sMinor); err != nil {
+		return nil, err
+	}
+
+	// Never pick a latest at random among several
+	if err := checkSingleLatest(versions.Versions); err != nil {
+		if !opts.Repair {
+			return nil, err
+		}
+		kept, cleared := repairLatest(versions.Versions)
+		printProgress("Repairing: kept %s as latest, cleared %s\n", kept, strings.Join(cleared, ", "))
 	}
 
 	// Find current latest
-	var oldLatest *Version
 	oldLatestIdx := -1
-	for i := range versions.Versions {
-		if versions.Versions[i].Latest {
-			oldLatest = &versions.Versions[i]
-			oldLatestIdx = i
-			break
+	previousLatest := ""
+	if !firstVersion {
+		oldLatestIdx, err = latestIndex(versions.Versions)
+		if err != nil {
+			return nil, fmt.Errorf("%w in %s", err, dataFile)
 		}
+		previousLatest = versions.Versions[oldLatestIdx].Tag
 	}
-
-	if oldLatest == nil {
-		log.Fatal("No current latest version found in data file")
+	if opts.Beta {
+		if semver.Prerelease(tag) == "" {
+			return nil, invalidf("--beta needs a prerelease tag such as v0.15.0-rc.1, got %s", tag)
+		}
+		if firstVersion {
+			return nil, invalidf("--beta needs a latest version to stay the stable one")
+		}
 	}
+	keepLatest := opts.NoDemote || opts.Beta
 
 	// Ensure no duplicates
-	for i := range versions.Versions {
-		if versions.Versions[i].Tag == tag {
-			log.Fatalf("Version %s already exists", tag)
+	if existing := findDuplicateTag(tag, versions.Versions); existing != "" {
+		return nil, fmt.Errorf("Version %s %w (as %s)", tag, ErrAlreadyExists, existing)
+	}
+
+	if oldLatestIdx != -1 {
+		if err := checkReleaseDateOrder(versions.Versions[oldLatestIdx], releaseDate); err != nil {
+			if opts.StrictDates {
+				return nil, err
+			}
+			opts.Warnings.add("%v", err)
+		}
+	}
+
+	if firstVersion {
+		printProgress("No version yet, %s will be the first one\n", tag)
+	} else {
+		printProgress("Current latest: %s\n", previousLatest)
+	}
+	printProgress("New version: %s\n", tag)
+
+	// The root index redirects to the latest version by itself, it is only
+	// written to bootstrap a project, unless the team manages it
+	createRootIndex := !indexExists && !opts.NoIndexUpdate
+
+	newVersionDir, err := safeVersionDir(baseDir, majorMinor)
+	if err != nil {
+		return nil, err
+	}
+	newVersionPath := filepath.Join(newVersionDir, "_index.md")
+
+	// Render the landing page up front, so that a broken template fails
+	// before anything is written
+	var landingPage string
+	if !opts.NoCopy {
+		landingPage, err = renderVersionLandingPage(opts.LandingTemplate, versions.longName(project), project, majorMinor)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkTOMLFrontMatter(newVersionPath, landingPage); err != nil {
+			return nil, err
+		}
+	}
+
+	// Copying over the content of another major.minor release needs --force,
+	// patch releases reuse the folder of their major.minor
+	if !opts.NoCopy && !opts.Force {
+		empty, err := dirIsEmpty(newVersionDir)
+		if err != nil {
+			return nil, err
+		}
+		if !empty && !isDirectoryUsedByOtherRelease(majorMinor, tag, versions.Versions) {
+			return nil, fmt.Errorf("%s %w and is not empty, use --force to copy over it", newVersionDir, ErrAlreadyExists)
 		}
 	}
 
-	fmt.Printf("Current latest: %s\n", oldLatest.Tag)
-	fmt.Printf("New version: %s\n", tag)
+	// With --no-copy, another pipeline is expected to provide the content
+	if opts.NoCopy {
+		if _, err := os.Stat(newVersionDir); os.IsNotExist(err) {
+			opts.Warnings.add("%s does not exist, make sure it is provided with the release", newVersionDir)
+		}
+	}
 
-	// Update TOML: mark old as not latest, add new version
-	versions.Versions[oldLatestIdx].Latest = false
+	if opts.PlanOutput != nil {
+		var plan Plan
+		if previousLatest != "" && !keepLatest {
+			plan.Steps = append(plan.Steps, Step{Type: StepDemote, Path: dataFile, Detail: fmt.Sprintf("%s is no longer latest", previousLatest)})
+		}
+		channel := "latest"
+		if opts.Beta {
+			channel = "beta"
+		}
+		plan.Steps = append(plan.Steps, Step{Type: StepWriteTOML, Path: dataFile, Detail: fmt.Sprintf("add %s as %s", tag, channel)})
+		if !opts.NoCopy {
+			plan.Steps = append(plan.Steps,
+				Step{Type: StepMkdir, Path: newVersionDir},
+				Step{Type: StepCopy, Path: newVersionDir, Detail: fmt.Sprintf("from %s", sourceDir)},
+				Step{Type: StepWriteIndex, Path: newVersionPath, Detail: fmt.Sprintf("replace %s with %s", sourceName, majorMinor)},
+			)
+		}
+		if createRootIndex {
+			plan.Steps = append(plan.Steps, Step{Type: StepWriteRootIndex, Path: rootIndexPath})
+		}
+		if !opts.NoCopy {
+			plan.Steps = append(plan.Steps, Step{Type: StepWeights, Path: baseDir})
+		}
+		if opts.ArchiveZip != "" {
+			plan.Steps = append(plan.Steps, Step{Type: StepArchive, Path: opts.ArchiveZip, Detail: fmt.Sprintf("from %s", sourceDir)})
+		}
+		if opts.MaxVersions > 0 {
+			proposed := append([]Version{{Tag: tag, Latest: !opts.Beta}}, versions.Versions...)
+			if oldLatestIdx != -1 && !keepLatest {
+				proposed[oldLatestIdx+1].Latest = false
+			}
+			kept, pruned := pruneVersions(proposed, opts.MaxVersions)
+			for _, v := range pruned {
+				plan.Steps = append(plan.Steps, Step{Type: StepPrune, Path: dataFile, Detail: fmt.Sprintf("remove %s", v.Tag)})
+			}
+			if opts.PruneContent {
+				for _, folder := range prunedFolders(pruned, kept) {
+					plan.Steps = append(plan.Steps, Step{Type: StepPrune, Path: filepath.Join(baseDir, folder)})
+				}
+			}
+		}
+		if err := plan.Write(opts.PlanOutput); err != nil {
+			return nil, err
+		}
+	}
+
+	changes := &Changeset{
+		Project:            project,
+		PreviousLatest:     previousLatest,
+		PreviousKeptLatest: previousLatest != "" && keepLatest,
+		NewLatest:          tag,
+		GoVersion:          goVersion,
+		DataFile:           relativeToRoot(opts.Root, dataFile),
+		Beta:               opts.Beta,
+		VersionDir:         relativeToRoot(opts.Root, newVersionDir),
+	}
 
+	// Update TOML: mark old as not latest, add new version.
+	// Labels baked into the human readable versions, current or past ones,
+	// are dropped, since the templates append the label themselves.
+	labels := []string{versions.latestLabel(), defaultLatestLabel, opts.LatestLabel}
+	if opts.LatestLabel != "" {
+		versions.LatestLabel = opts.LatestLabel
+		if opts.LatestLabel == defaultLatestLabel {
+			versions.LatestLabel = ""
+		}
+	}
+	var previousK8sVersions []string
+	if oldLatestIdx != -1 {
+		switch {
+		case opts.Beta:
+			printProgress("Keeping %s as latest, %s is a beta (--beta)\n", previousLatest, tag)
+		case opts.NoDemote:
+			printProgress("Keeping %s as latest too (--no-demote), reconcile the data file before the next release\n", previousLatest)
+		default:
+			versions.Versions[oldLatestIdx].Latest = false
+			versions.Versions[oldLatestIdx].Version = stripLatestLabel(versions.Versions[oldLatestIdx].Version, labels...)
+		}
+		previousK8sVersions = versions.Versions[oldLatestIdx].TestedK8sVersions
+	}
+
+	endOfLife, err := versions.endOfLife(releaseDate)
+	if err != nil {
+		return nil, err
+	}
 	newVersion := Version{
 		Tag:               tag,
-		Latest:            true,
+		Latest:            !opts.Beta,
 		ReleaseDate:       releaseDate,
-		TestedK8sVersions: strings.Split(testedK8sVersions, ","),
-		EndOfLife:         "",
+		TestedK8sVersions: splitK8sVersions(testedK8sVersions),
+		EndOfLife:         endOfLife,
+		Version:           stripLatestLabel(displayVersion, labels...),
 	}
 	versions.Versions = append([]Version{newVersion}, versions.Versions...)
+	changes.TestedK8sVersions = newVersion.TestedK8sVersions
+
+	// Keep the version switcher manageable
+	var pruned []Version
+	versions.Versions, pruned = pruneVersions(versions.Versions, opts.MaxVersions)
+	for _, v := range pruned {
+		printProgress("Pruning %s (--max-versions %d)\n", v.Tag, opts.MaxVersions)
+		changes.Pruned = append(changes.Pruned, v.Tag)
+	}
+	if previousLatest != "" {
+		changes.K8sAdded, changes.K8sRemoved = diffK8sVersions(previousK8sVersions, newVersion.TestedK8sVersions)
+	}
+
+	if opts.DryRun {
+		if opts.DiffOutput != nil {
+			if err := writeAddDiff(opts.DiffOutput, opts.Settings, opts.Root, dataFile, versions, rootIndexPath, createRootIndex, project); err != nil {
+				return nil, err
+			}
+		}
+		printProgress("Dry run: no changes made\n")
+		return nil, nil
+	}
+
+	// From the first mutation on, undo everything if a later step fails
+	undo, err := newRollback(opts.Settings, dataFile)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			undo.run()
+		}
+	}()
 
 	// Write TOML
-	if err := writeVersions(dataFile, versions); err != nil {
-		log.Fatal(err)
+	jsonFile := versionsJSONFile(dataFile)
+	_, jsonStatErr := os.Stat(jsonFile)
+	stop = opts.Timings.start(PhaseWriteTOML)
+	if err := opts.Settings.writeVersions(dataFile, versions); err != nil {
+		return nil, err
 	}
-	fmt.Printf("Updated %s\n", dataFile)
+	stop()
+	printStep("Updated %s", dataFile)
+	changes.recordModified(opts.Root, dataFile)
+	// The JSON mirror is kept in sync once it exists, or created by --emit-json
+	if jsonStatErr == nil {
+		changes.recordModified(opts.Root, jsonFile)
+	} else if _, err := os.Stat(jsonFile); err == nil {
+		changes.FilesCreated = append(changes.FilesCreated, relativeToRoot(opts.Root, jsonFile))
+	}
+
+	if opts.NoCopy {
+		printProgress("Not copying content to %s (--no-copy)\n", newVersionDir)
+	} else {
+		// Create directory using major.minor
+		// Record which files the copy will create or overwrite
+		if err := changes.recordCopy(opts.Root, sourceDir, newVersionDir, opts.CopyExcludeDirs); err != nil {
+			return nil, err
+		}
+
+		// ALWAYS create/update directory (even if it exists)
+		printStep("Creating/updating release directory %s", newVersionDir)
+		undo.removeIfCreated(newVersionDir)
+		if err := undo.restoreTree(sourceDir, newVersionDir, opts.CopyExcludeDirs); err != nil {
+			return nil, err
+		}
+		stop = opts.Timings.start(PhaseMkdir)
+		if err := opts.Settings.mkdirGenerated(newVersionDir); err != nil {
+			return nil, err
+		}
+		stop()
+
+		// ALWAYS copy source content (overwrites if directory exists)
+		printStep("Copying %s content to %s", sourceName, newVersionDir)
+		copyOpts := CopyOptions{SkipUnchanged: opts.SkipUnchanged, Dereference: opts.DerefSymlinks, Stats: opts.Timings.copyStats(), ExcludeDirs: opts.CopyExcludeDirs}
+		if !opts.Quiet {
+			copyOpts.Progress = printCopyProgress(progressOut)
+		}
+		stop = opts.Timings.start(PhaseCopy)
+		if err := copyContent(sourceDir, newVersionDir, copyOpts); err != nil {
+			return nil, fmt.Errorf("Failed to copy content: %w", err)
+		}
+		stop()
+
+		if opts.StripDrafts {
+			removed, err := stripDrafts(newVersionDir)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to strip drafts: %w", err)
+			}
+			for _, page := range removed {
+				printProgress("Removed draft %s\n", page)
+				changes.recordRemoved(opts.Root, page)
+			}
+		}
+
+		// Adapt version landing page
+		stop = opts.Timings.start(PhaseIndex)
+		// Read the file and replace the source name (e.g. "Unreleased", case insensitive) with majorMinor.
+		// Without a landing page in the source, or with a custom template, use the rendered one.
+		content, err := os.ReadFile(newVersionPath)
+		if os.IsNotExist(err) || opts.LandingTemplate != "" {
+			content, err = []byte(landingPage), nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read version file: %w", err)
+		}
 
-	// Create directory using major.minor
-	majorMinor := extractMajorMinor(tag)
-	newVersionDir := filepath.Join(baseDir, majorMinor)
+		// Replace the source name (case insensitive) with majorMinor
+		text := string(content)
+		re := regexp.MustCompile(`(?i)` + regexp.QuoteMeta(sourceName))
+		text = re.ReplaceAllString(text, majorMinor)
+		if goVersion != "" {
+			text = setCascadeParam(text, "project_go_version", goVersion)
+		}
+		for _, p := range cascadeParams {
+			text = setCascadeParam(text, p.Key, p.Value)
+		}
 
-	// ALWAYS create/update directory (even if it exists)
-	fmt.Printf("Creating/updating release directory %s\n", newVersionDir)
-	if err := os.MkdirAll(newVersionDir, 0755); err != nil {
-		log.Fatal(err)
+		// Write the updated content back, keeping the newline convention of the source
+		text = normalizeMarkdown(text, usesCRLF(string(content)))
+		if err := checkTOMLFrontMatter(newVersionPath, text); err != nil {
+			return nil, err
+		}
+		if err := opts.Settings.writeGeneratedFile(newVersionPath, []byte(text)); err != nil {
+			return nil, err
+		}
+
+		stop()
+		printStep("Overwritten %s", newVersionPath)
 	}
 
-	// ALWAYS copy unreleased content (overwrites if directory exists)
-	fmt.Printf("Copying unreleased content to %s\n", newVersionDir)
-	if err := CopyDir(filepath.Join(baseDir, "unreleased"), newVersionDir); err != nil {
-		log.Fatalf("Failed to copy content: %v", err)
+	// A bootstrapped project also needs its redirect to the latest version
+	if createRootIndex {
+		undo.removeIfCreated(rootIndexPath)
+		stop = opts.Timings.start(PhaseIndex)
+		rootIndex := renderRootIndex(versions.longName(project), project)
+		if err := checkTOMLFrontMatter(rootIndexPath, rootIndex); err != nil {
+			return nil, err
+		}
+		if err := opts.Settings.writeGeneratedFile(rootIndexPath, []byte(rootIndex)); err != nil {
+			return nil, err
+		}
+		stop()
+		printStep("Created %s", rootIndexPath)
+		changes.FilesCreated = append(changes.FilesCreated, relativeToRoot(opts.Root, rootIndexPath))
 	}
 
-	// Adapt version landing page
-	newVersionPath := filepath.Join(newVersionDir, "_index.md")
+	// Hosts serving the latest version through a symlink, rather than the
+	// redirect of the root index, need it to follow the new version
+	if opts.SymlinkLatest && !opts.Beta {
+		link := filepath.Join(baseDir, latestLink)
+		_, linkStatErr := os.Lstat(link)
+		if err := undo.restoreIfChanged(link); err != nil {
+			return nil, err
+		}
+		if err := updateLatestLink(baseDir, majorMinor); err != nil {
+			opts.Warnings.add("cannot update the %s symlink: %v", latestLink, err)
+		} else {
+			printProgress("Pointed %s to %s\n", link, majorMinor)
+			if linkStatErr == nil {
+				changes.recordModified(opts.Root, link)
+			} else {
+				changes.FilesCreated = append(changes.FilesCreated, relativeToRoot(opts.Root, link))
+			}
+		}
+	}
 
-	// Read the file and replace "Unreleased" (case insensitive) with majorMinor
-	content, err := os.ReadFile(newVersionPath)
-	if err != nil {
-		log.Fatalf("Failed to read version file: %v", err)
+	// Keep the sidebar ordered now that a new version is in
+	if !opts.NoCopy {
+		for _, folder := range versionFolders(versions.Versions) {
+			page := filepath.Join(baseDir, folder, "_index.md")
+			if _, err := os.Stat(page); err == nil {
+				if err := undo.restoreIfChanged(page); err != nil {
+					return nil, err
+				}
+			}
+		}
+		updatedPages, err := opts.Settings.recomputeWeights(baseDir, versions)
+		if err != nil {
+			return nil, err
+		}
+		for _, page := range updatedPages {
+			// The landing page of the version is already recorded
+			if page != newVersionPath {
+				changes.recordModified(opts.Root, page)
+			}
+		}
 	}
 
-	// Replace "Unreleased" (case insensitive) with majorMinor
-	text := string(content)
-	re := regexp.MustCompile(`(?i)unreleased`)
-	text = re.ReplaceAllString(text, majorMinor)
+	// The bundle is built from the source, like the copy
+	if opts.ArchiveZip != "" {
+		if err := undo.restoreIfChanged(opts.ArchiveZip); err != nil {
+			return nil, err
+		}
+		if err := ArchiveZip(osFS{}, sourceDir, opts.ArchiveZip, opts.CopyExcludeDirs); err != nil {
+			return nil, fmt.Errorf("Failed to archive %s: %w", sourceDir, err)
+		}
+		printStep("Archived %s into %s", sourceDir, opts.ArchiveZip)
+	}
 
-	// Write the updated content back
-	if err := os.WriteFile(newVersionPath, []byte(text), 0644); err != nil {
-		log.Fatal(err)
+	// Checksum the folder last, once its landing page is final
+	if opts.Manifest && !opts.NoCopy {
+		manifestPath := filepath.Join(newVersionDir, manifestFile)
+		_, statErr := os.Lstat(manifestPath)
+		if err := undo.restoreIfChanged(manifestPath); err != nil {
+			return nil, err
+		}
+		if _, err := opts.Settings.writeManifest(newVersionDir); err != nil {
+			return nil, fmt.Errorf("Failed to write the manifest: %w", err)
+		}
+		printStep("Wrote %s", manifestPath)
+		if statErr == nil {
+			changes.recordModified(opts.Root, manifestPath)
+		} else {
+			changes.FilesCreated = append(changes.FilesCreated, relativeToRoot(opts.Root, manifestPath))
+		}
 	}
 
-	fmt.Printf("Overwritten %s\n", newVersionPath)
+	// Deleting content cannot be rolled back, so it comes last
+	if opts.PruneContent {
+		for _, folder := range prunedFolders(pruned, versions.Versions) {
+			dir, err := safeVersionDir(baseDir, folder)
+			if err != nil {
+				return nil, err
+			}
+			if err := os.RemoveAll(dir); err != nil {
+				return nil, fmt.Errorf("Failed to delete pruned directory: %w", err)
+			}
+			changes.FoldersRemoved = append(changes.FoldersRemoved, relativeToRoot(opts.Root, dir))
+			printStep("Deleted %s", dir)
+		}
+	}
 
-	fmt.Printf("\nRelease %s added successfully!\n", tag)
-	fmt.Printf("Documentation will be available at: /%s-docs/%s/\n", project, majorMinor)
-	fmt.Printf("Next steps:\n")
-	fmt.Printf("1. Review the changes\n")
-	fmt.Printf("2. Commit and push\n")
+	return changes, nil
 }
 
-func handleRemove(project string, tag string) {
+func handleRemove(settings Settings, project string, tag string, keepContent bool) {
 	// Validate inputs
 	if project == "" || tag == "" {
 		printReleaseUsage()
 		os.Exit(1)
 	}
 
-	if project != "eso" && project != "reloader" {
-		log.Fatalf("project must be 'eso' or 'reloader', got: %s", project)
+	removed, err := removeRelease(settings, "", project, tag, keepContent)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	fmt.Printf("\nVersion %s deleted successfully!\n", tag)
+	if removed.Latest {
+		fmt.Printf("IMPORTANT: No version is marked as latest now. Please manually mark another version as latest in %s.\n", settings.dataFilePath("", project))
 	}
+}
 
+// removeRelease deletes tag from the project data file, and its content
+// folder unless other releases still use it or keepContent is set, e.g.
+// to keep the pages reachable by their URL without listing the version.
+// It returns the removed version.
+func removeRelease(settings Settings, root string, project string, tag string, keepContent bool) (*Version, error) {
+	if err := validateProject(project); err != nil {
+		return nil, err
+	}
+
+	tag = normalizeVersion(tag)
 	if !semver.IsValid(tag) {
-		log.Fatalf("Invalid semver tag: %s", tag)
+		return nil, invalidf("Invalid semver tag: %s", tag)
 	}
 
 	// Determine paths
-	baseDir := filepath.Join("content", "en", fmt.Sprintf("%s-docs", project))
-	dataFile := filepath.Join("data", fmt.Sprintf("%s_versions.toml", project))
+	baseDir := settings.docsDir(root, project)
+	dataFile := settings.dataFilePath(root, project)
+
+	// Prevent concurrent runs from overwriting each other
+	unlock, err := lockDataFile(dataFile)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
 	// Read versions
 	versions, err := readVersions(dataFile)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
 	// Find version to remove
 	removeIdx := -1
-	var versionToRemove *Version
+	var versionToRemove Version
 	for i := range versions.Versions {
-		if versions.Versions[i].Tag == tag {
+		if compareVersions(versions.Versions[i].Tag, tag) == 0 {
 			removeIdx = i
-			versionToRemove = &versions.Versions[i]
+			versionToRemove = versions.Versions[i]
 			break
 		}
 	}
 
 	if removeIdx == -1 {
-		log.Fatalf("Version %s not found", tag)
+		return nil, invalidf("Version %s not found", tag)
 	}
 
 	// Warn if removing latest
 	if versionToRemove.Latest {
-		log.Printf("Warning: Removing latest version. Mark another version as latest manually.")
+		logWarning("Removing latest version. Mark another version as latest manually.")
 	}
 
 	// Extract major.minor
-	majorMinor := extractMajorMinor(tag)
-	versionDir := filepath.Join(baseDir, majorMinor)
+	majorMinor, err := extractMajorMinor(tag)
+	if err != nil {
+		return nil, err
+	}
+	versionDir, err := safeVersionDir(baseDir, majorMinor)
+	if err != nil {
+		return nil, err
+	}
 
 	// Remove from slice
 	versions.Versions = append(versions.Versions[:removeIdx], versions.Versions[removeIdx+1:]...)
 
 	// Check if directory is still used
-	if isDirectoryUsedByOtherRelease(majorMinor, tag, versions.Versions) {
+	switch {
+	case keepContent:
+		fmt.Printf("Keeping directory %s (--keep-content)\n", versionDir)
+	case isDirectoryUsedByOtherRelease(majorMinor, tag, versions.Versions):
 		fmt.Printf("Directory %s still used by other releases, keeping it\n", versionDir)
-	} else {
+	default:
 		fmt.Printf("Deleting directory %s\n", versionDir)
 		if err := os.RemoveAll(versionDir); err != nil {
-			log.Fatalf("Failed to delete directory: %v", err)
+			return nil, fmt.Errorf("Failed to delete directory: %w", err)
 		}
-		fmt.Printf("Deleted %s\n", versionDir)
+		printStep("Deleted %s", versionDir)
 	}
 
 	// Write updated TOML
-	if err := writeVersions(dataFile, versions); err != nil {
-		log.Fatal(err)
+	if err := settings.writeVersions(dataFile, versions); err != nil {
+		return nil, err
 	}
-	fmt.Printf("Updated %s (removed %s)\n", dataFile, tag)
+	printStep("Updated %s (removed %s)", dataFile, tag)
 
-	fmt.Printf("\nVersion %s deleted successfully!\n", tag)
-	if versionToRemove.Latest {
-		fmt.Printf("IMPORTANT: No version is marked as latest now. Please manually mark another version as latest in %s.\n", dataFile)
+	return &versionToRemove, nil
+}
+
+// printCopyProgress returns a CopyOptions.Progress callback rendering a
+// single, updated in place, progress line on w
+func printCopyProgress(w io.Writer) func(copied, total int) {
+	return func(copied, total int) {
+		fmt.Fprintf(w, "\rCopied %d/%d files", copied, total)
+		if copied == total {
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+// writeAddDiff writes to w the unified diffs of the files an add would
+// write, computed from the proposed versions
+func writeAddDiff(w io.Writer, settings Settings, root, dataFile string, versions *VersionsData, rootIndexPath string, createRootIndex bool, project string) error {
+	before, err := os.ReadFile(dataFile)
+	if err != nil {
+		return err
+	}
+	after, err := settings.encodeVersions(versionsFormat(dataFile), versions)
+	if err != nil {
+		return err
+	}
+	diff := unifiedDiff(relativeToRoot(root, dataFile), string(before), string(after))
+	if createRootIndex {
+		diff += unifiedDiff(relativeToRoot(root, rootIndexPath), "", renderRootIndex(versions.longName(project), project))
 	}
+	_, err = io.WriteString(w, diff)
+	return err
 }
 
+// copySourceName returns the content folder name matching the --copy-from value.
+// Versions can be given as full tags (v0.15.3) or folder names (v0.15).
+func copySourceName(copyFrom string) (string, error) {
+	if copyFrom == "" || copyFrom == "unreleased" {
+		return "unreleased", nil
+	}
+	copyFrom = normalizeVersion(copyFrom)
+	if !semver.IsValid(copyFrom) {
+		return "", invalidf("--copy-from must be 'unreleased' or a version, got: %s", copyFrom)
+	}
+	return semver.MajorMinor(copyFrom), nil
+}
+
+var (
+	// ErrDataFileMissing is returned when a project data file does not exist
+	ErrDataFileMissing = errors.New("data file not found")
+	// ErrNoVersions is returned when a project data file has no versions
+	ErrNoVersions = errors.New("no versions found")
+	// ErrNoLatest is returned when no version is marked as latest
+	ErrNoLatest = errors.New("no current latest version found")
+)
+
+// readVersions decodes a project data file, in TOML or YAML.
+// It fails with ErrDataFileMissing or ErrNoVersions when there is no data
+// to work on, the latter along with the decoded header.
 func readVersions(filename string) (*VersionsData, error) {
-	var data VersionsData
-	if _, err := toml.DecodeFile(filename, &data); err != nil {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrDataFileMissing, filename)
+		}
 		return nil, err
 	}
+	return parseVersions(filename, versionsFormat(filename), content)
+}
+
+// parseVersions decodes content, read from the data file filename, in the
+// given format
+func parseVersions(filename string, format string, content []byte) (*VersionsData, error) {
+	var data VersionsData
+	if err := decodeVersions(format, content, &data); err != nil {
+		return nil, invalidf("cannot parse %s: %w", filename, err)
+	}
+	if len(data.Versions) == 0 {
+		// Still return the header, e.g. to bootstrap the first version
+		return &data, fmt.Errorf("%w in %s", ErrNoVersions, filename)
+	}
 	return &data, nil
 }
 
-func writeVersions(filename string, data *VersionsData) error {
-	f, err := os.Create(filename)
+// latestIndex returns the index of the first version marked as latest,
+// or ErrNoLatest
+func latestIndex(versions []Version) (int, error) {
+	for i := range versions {
+		if versions[i].Latest {
+			return i, nil
+		}
+	}
+	return -1, ErrNoLatest
+}
+
+// writeVersions atomically replaces filename with the encoded data:
+// the content is written to a temporary file which is then renamed,
+// so readers never observe a partially written file.
+func (s Settings) writeVersions(filename string, data *VersionsData) error {
+	if err := s.backupVersions(filename); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmpName := f.Name()
+	defer os.Remove(tmpName) // no-op once renamed
+
+	content, err := s.encodeVersions(versionsFormat(filename), data)
 	if err != nil {
+		f.Close()
 		return err
 	}
-	defer f.Close()
+	// Keep the line endings of the existing file to avoid whitespace diffs
+	if existing, err := os.ReadFile(filename); err == nil && usesCRLF(string(existing)) {
+		content = bytes.ReplaceAll(content, []byte("\n"), []byte("\r\n"))
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpName, s.fileMode()); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, filename); err != nil {
+		return err
+	}
+	return s.syncVersionsJSON(filename, data)
+}
+
+// versionsJSONFile returns the JSON mirror of a data file,
+// e.g. data/eso_versions.json for data/eso_versions.toml
+func versionsJSONFile(filename string) string {
+	return strings.TrimSuffix(filename, filepath.Ext(filename)) + ".json"
+}
+
+// syncVersionsJSON writes the JSON mirror of filename, consumed by the
+// client-side version switcher, when EmitJSON is set or the mirror
+// already exists, so that it never goes stale.
+// With ArtifactsDir, the mirror requested by EmitJSON is written there
+// instead of next to filename.
+func (s Settings) syncVersionsJSON(filename string, data *VersionsData) error {
+	jsonFile := versionsJSONFile(filename)
+	var targets []string
+	if _, err := os.Stat(jsonFile); !os.IsNotExist(err) || (s.EmitJSON && s.ArtifactsDir == "") {
+		targets = append(targets, jsonFile)
+	}
+	if s.EmitJSON && s.ArtifactsDir != "" {
+		artifact, err := s.artifactPath(jsonFile)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, artifact)
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	out, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	for _, target := range targets {
+		if err := s.writeGeneratedFile(target, append(out, '\n')); err != nil {
+			return err
+		}
+		printStep("Updated %s", target)
+	}
+	return nil
+}
+
+// rawBaseURLEnv is the environment variable defaulting --raw-base-url
+const rawBaseURLEnv = "RELEASE_RAW_BASE_URL"
+
+// httpClient fetches the go.mod files and release notes by default, through
+// the proxy of the environment (HTTPS_PROXY)
+var httpClient = &http.Client{}
+
+// httpClientFor returns a client sending its requests with transport, or
+// httpClient when transport is nil
+func httpClientFor(transport http.RoundTripper) *http.Client {
+	if transport == nil {
+		return httpClient
+	}
+	return &http.Client{Transport: transport}
+}
+
+// resolveGoModURL returns the go.mod location of the tag of project,
+// prefixed with its TagPrefix and pointing to its GoModPath when set.
+// When rawBaseURL is set, it replaces the scheme and host of the location,
+// and prefixes its path, so e.g. https://mirror.example.com/raw serves
+// https://raw.githubusercontent.com/org/repo/v1.0.0/go.mod from
+// https://mirror.example.com/raw/org/repo/v1.0.0/go.mod.
+func resolveGoModURL(project string, tag string, rawBaseURL string) (string, error) {
+	location := fmt.Sprintf(projects[project].GoModLocation, gitTag(project, stripTagPrefix(project, tag)))
+	if goModPath := projects[project].GoModPath; goModPath != "" {
+		root, found := strings.CutSuffix(location, "/go.mod")
+		if !found {
+			return "", fmt.Errorf("cannot use go.mod path %s: go.mod location %q does not end with /go.mod", goModPath, location)
+		}
+		location = root + "/" + strings.TrimPrefix(path.Clean("/"+goModPath), "/")
+	}
+	if rawBaseURL == "" {
+		return location, nil
+	}
+
+	base, err := url.Parse(rawBaseURL)
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return "", invalidf("invalid raw base URL %q, expected e.g. https://mirror.example.com", rawBaseURL)
+	}
+	u, err := url.Parse(location)
+	if err != nil {
+		return "", invalidf("invalid go.mod location %q: %w", location, err)
+	}
+	u.Scheme = base.Scheme
+	u.Host = base.Host
+	u.User = base.User
+	u.Path = path.Join("/", base.Path, u.Path)
+	return u.String(), nil
+}
+
+func (s Settings) fetchGoMod(client *http.Client, url string) ([]byte, error) {
+	if body, ok := s.readCachedGoMod(url); ok {
+		return body, nil
+	}
 
-	encoder := toml.NewEncoder(f)
-	return encoder.Encode(data)
-}
-
-// updateProjectIndex is no longer needed as the redirect layout
-// now reads the latest version dynamically from the data files.
-// Keeping the function commented for reference in case manual updates are needed.
-//
-// func updateProjectIndex(filename string, project string, newVersion string) error {
-// 	content, err := os.ReadFile(filename)
-// 	if err != nil {
-// 		return err
-// 	}
-//
-// 	text := string(content)
-//
-// 	// Replace the "go to latest" link
-// 	// Match pattern like: [latest version](/eso-docs/v0.14/)
-// 	pattern := fmt.Sprintf(`\[latest version\]\(/%s-docs/v[\d.]+/\)`, project)
-// 	replacement := fmt.Sprintf(`[latest version](/%s-docs/%s/)`, project, newVersion)
-//
-// 	re, err := regexp.Compile(pattern)
-// 	if err != nil {
-// 		return err
-// 	}
-//
-// 	text = re.ReplaceAllString(text, replacement)
-//
-// 	return os.WriteFile(filename, []byte(text), 0644)
-// }
-
-func fetchGoMod(url string) ([]byte, error) {
 	// Fetch the go.mod file
-	resp, err := http.Get(url)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFetchGoMod, err)
+	}
+	resp, err := s.doWithRetry(client, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch go.mod: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrFetchGoMod, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch go.mod: HTTP %d", resp.StatusCode)
+		return nil, fmt.Errorf("%w: HTTP %d", ErrFetchGoMod, resp.StatusCode)
 	}
 
 	// Read the response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("%w: failed to read response: %w", ErrFetchGoMod, err)
 	}
+	s.writeCachedGoMod(url, body)
 	return body, nil
 }
 
+// fetchHighestClientGoMod fetches the go.mod at each of urls, for releases
+// spanning several modules, and returns the one requiring the highest
+// client-go along with its URL. Without any client-go, the first one wins.
+func (s Settings) fetchHighestClientGoMod(client *http.Client, urls []string) (string, string, error) {
+	var bestURL, bestGoMod, bestClientGo string
+	for _, url := range urls {
+		body, err := s.fetchGoMod(client, url)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to fetch from %s: %w", url, err)
+		}
+		clientGo, err := parseK8sClientGoVersion(string(body))
+		if err != nil {
+			clientGo = ""
+		}
+		if bestURL == "" || (clientGo != "" && (bestClientGo == "" || semver.Compare(clientGo, bestClientGo) > 0)) {
+			bestURL, bestGoMod, bestClientGo = url, string(body), clientGo
+		}
+	}
+	if len(urls) > 1 && bestClientGo != "" {
+		printProgress("Using %s, it requires the highest client-go (%s)\n", bestURL, bestClientGo)
+	}
+	return bestURL, bestGoMod, nil
+}
+
 func parseK8sClientGoVersion(goModContent string) (string, error) {
-	lines := strings.Split(goModContent, "\n")
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		// Look for lines that start with k8s.io/client-go
-		// It should look like this for version 1.35:
-		// k8s.io/client-go v0.35.0
-		if strings.HasPrefix(line, "k8s.io/client-go") {
-			// Split by whitespace to get the module and version
-			parts := strings.Fields(line)
-			if len(parts) >= 2 {
-				// Return the version (second field if no replacement, 4th if replacement)
-				if parts[1] == "=>" {
-					return parts[3], nil
-				}
-				return parts[1], nil
-			}
+	return parseModuleVersion(goModContent, "k8s.io/client-go")
+}
+
+// parseModuleVersion returns the version of modulePath required by
+// goModContent, once its replace directives are applied
+func parseModuleVersion(goModContent string, modulePath string) (string, error) {
+	// Unlike ParseLax, Parse keeps the replace directives
+	f, err := modfile.Parse("go.mod", []byte(goModContent), nil)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse go.mod: %w", err)
+	}
+
+	version := ""
+	for _, r := range f.Require {
+		if r.Mod.Path == modulePath {
+			version = r.Mod.Version
+			break
 		}
 	}
+	if version == "" {
+		return "", fmt.Errorf("%s not found in go.mod", modulePath)
+	}
 
-	return "", fmt.Errorf("k8s.io/client-go not found in go.mod")
+	// A replace directive, for all versions or the required one, pins the
+	// effective version. Replacements by a local directory have no version
+	// and keep the required one.
+	for _, r := range f.Replace {
+		if r.Old.Path != modulePath || (r.Old.Version != "" && r.Old.Version != version) {
+			continue
+		}
+		if r.New.Version != "" {
+			return r.New.Version, nil
+		}
+	}
+	return version, nil
+}
+
+// parseGoVersion returns the go directive of goModContent,
+// or an empty string when there is none
+func parseGoVersion(goModContent string) string {
+	f, err := modfile.ParseLax("go.mod", []byte(goModContent), nil)
+	if err != nil || f.Go == nil {
+		return ""
+	}
+	return f.Go.Version
+}
+
+// reportModules prints the version of each of modules found in goModContent
+func reportModules(w io.Writer, goModContent string, modules []string) error {
+	for _, module := range modules {
+		version, err := parseModuleVersion(goModContent, module)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s %s\n", module, version)
+	}
+	return nil
 }
 
 // convertClientGoToRealK8sVersion converts client-go version to Kubernetes version
@@ -1,6 +1,18 @@
 package main
 
-import "testing"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
 
 func Test_convertClientGoToRealK8sVersion(t *testing.T) {
 	type args struct {
@@ -73,11 +85,14 @@ func TestExtractMajorMinor(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		result := extractMajorMinor(tt.input)
-		if result != tt.expected {
-			t.Errorf("extractMajorMinor(%s) = %s; want %s", tt.input, result, tt.expected)
+		result, err := extractMajorMinor(tt.input)
+		if err != nil || result != tt.expected {
+			t.Errorf("extractMajorMinor(%s) = %s, %v; want %s", tt.input, result, err, tt.expected)
 		}
 	}
+	if _, err := extractMajorMinor("latest"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("extractMajorMinor(latest) error = %v, want ErrInvalid", err)
+	}
 }
 
 func TestIsDirectoryUsedByOtherRelease(t *testing.T) {
@@ -120,3 +135,975 @@ func TestIsDirectoryUsedByOtherRelease(t *testing.T) {
 		})
 	}
 }
+
+func TestAddReleaseCopyFrom(t *testing.T) {
+	root := newTestRepo(t)
+	previous := filepath.Join(root, "content", "en", "eso-docs", "v0.14")
+	if err := CopyDir(filepath.Join(root, "content", "en", "eso-docs", "unreleased"), previous); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(previous, "_index.md"), []byte("+++\ntitle = \"ESO Documentation (v0.14)\"\n+++\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(previous, "guide", "page.md"), []byte("# Guide v0.14\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := addRelease(AddOptions{
+		Root:              root,
+		Project:           "eso",
+		Tag:               "v0.15.0",
+		TestedK8sVersions: "v1.33",
+		CopyFrom:          "v0.14.0",
+	})
+	if err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+
+	newDir := filepath.Join(root, "content", "en", "eso-docs", "v0.15")
+	page, err := os.ReadFile(filepath.Join(newDir, "guide", "page.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(page) != "# Guide v0.14\n" {
+		t.Errorf("page content = %q, want the v0.14 content", page)
+	}
+	index, err := os.ReadFile(filepath.Join(newDir, "_index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), "ESO Documentation (v0.15)") {
+		t.Errorf("landing page was not adapted to v0.15:\n%s", index)
+	}
+}
+
+func TestAddReleaseCopyFromMissing(t *testing.T) {
+	root := newTestRepo(t)
+	_, err := addRelease(AddOptions{
+		Root:              root,
+		Project:           "eso",
+		Tag:               "v0.15.0",
+		TestedK8sVersions: "v1.33",
+		CopyFrom:          "v0.13",
+	})
+	if err == nil || !strings.Contains(err.Error(), "Content source not found") {
+		t.Fatalf("addRelease() error = %v, want missing source error", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "content", "en", "eso-docs", "v0.15")); !os.IsNotExist(err) {
+		t.Errorf("v0.15 folder should not have been created")
+	}
+}
+
+func TestAddReleaseRejectsDuplicateTags(t *testing.T) {
+	for _, tag := range []string{"v0.14.0", "v0.14"} {
+		t.Run(tag, func(t *testing.T) {
+			root := newTestRepo(t)
+			dataFile := filepath.Join(root, "data", "eso_versions.toml")
+			before, err := os.ReadFile(dataFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = addRelease(AddOptions{Root: root, Project: "eso", Tag: tag, TestedK8sVersions: "v1.33"})
+			if err == nil || !strings.Contains(err.Error(), "already exists") {
+				t.Fatalf("addRelease() error = %v, want already exists", err)
+			}
+
+			after, err := os.ReadFile(dataFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(before) != string(after) {
+				t.Errorf("data file changed on rejected duplicate")
+			}
+		})
+	}
+}
+
+// The latest flag is the single source of truth of the latest version: the
+// layouts append the label to it, so demotion strips the label of the
+// previous latest however it was stored.
+func TestAddReleaseDemotesPreviousLatest(t *testing.T) {
+	for _, stored := range []string{"", "v0.14", "v0.14 (latest)", "v0.14 (Latest)", "v0.14(latest)", "v0.14 ( latest ) (latest)"} {
+		t.Run(stored, func(t *testing.T) {
+			root := newTestRepo(t)
+			dataFile := Settings{}.dataFilePath(root, "eso")
+			if err := (Settings{}).writeVersions(dataFile, &VersionsData{Versions: []Version{
+				{Tag: "v0.14.0", Latest: true, ReleaseDate: "2025-01-01", Version: stored},
+			}}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33"}); err != nil {
+				t.Fatalf("addRelease() error = %v", err)
+			}
+
+			versions, err := readVersions(dataFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var latest []string
+			for _, v := range versions.Versions {
+				if v.Latest {
+					latest = append(latest, v.Tag)
+				}
+			}
+			if len(latest) != 1 || latest[0] != "v0.15.0" {
+				t.Errorf("latest versions = %v, want [v0.15.0]", latest)
+			}
+			want := "v0.14"
+			if stored == "" {
+				want = ""
+			}
+			if got := versions.Versions[1].Version; got != want {
+				t.Errorf("demoted version = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestStripLatestLabel(t *testing.T) {
+	tests := []struct {
+		version string
+		labels  []string
+		want    string
+	}{
+		{version: "v0.14", labels: []string{defaultLatestLabel}, want: "v0.14"},
+		{version: " v0.14 (latest) ", labels: []string{defaultLatestLabel}, want: "v0.14"},
+		{version: "v0.14 (LATEST)", labels: []string{defaultLatestLabel}, want: "v0.14"},
+		{version: "v0.14( latest)", labels: []string{defaultLatestLabel}, want: "v0.14"},
+		{version: "v0.14 (latest) [current]", labels: []string{"[current]", defaultLatestLabel}, want: "v0.14"},
+		{version: "v0.14 [current] (latest)", labels: []string{"[current]", defaultLatestLabel}, want: "v0.14"},
+		{version: "v0.14 latest", labels: []string{defaultLatestLabel}, want: "v0.14 latest"},
+		{version: "v0.14 (latest)", labels: []string{"", " "}, want: "v0.14 (latest)"},
+	}
+	for _, tt := range tests {
+		if got := stripLatestLabel(tt.version, tt.labels...); got != tt.want {
+			t.Errorf("stripLatestLabel(%q, %q) = %q, want %q", tt.version, tt.labels, got, tt.want)
+		}
+	}
+}
+
+const sampleGoMod = `module github.com/external-secrets/external-secrets
+
+go 1.23.0
+
+require github.com/spf13/cobra v1.8.1
+
+require (
+	k8s.io/client-go v0.35.0
+	k8s.io/client-go-extra v0.1.0
+	sigs.k8s.io/controller-runtime v0.21.0
+)
+`
+
+func TestParseModuleVersion(t *testing.T) {
+	tests := []struct {
+		module  string
+		want    string
+		wantErr bool
+	}{
+		{module: "k8s.io/client-go", want: "v0.35.0"},
+		{module: "sigs.k8s.io/controller-runtime", want: "v0.21.0"},
+		{module: "github.com/spf13/cobra", want: "v1.8.1"},
+		{module: "k8s.io/api", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.module, func(t *testing.T) {
+			got, err := parseModuleVersion(sampleGoMod, tt.module)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseModuleVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseModuleVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseModuleVersionReplace(t *testing.T) {
+	const require = "module example.com/operator\n\ngo 1.24.0\n\nrequire k8s.io/client-go v0.34.0\n\n"
+	tests := []struct {
+		name    string
+		replace string
+		want    string
+	}{
+		{name: "all versions", replace: "replace k8s.io/client-go => k8s.io/client-go v0.35.0\n", want: "v0.35.0"},
+		{name: "required version", replace: "replace k8s.io/client-go v0.34.0 => k8s.io/client-go v0.35.0\n", want: "v0.35.0"},
+		{name: "fork", replace: "replace k8s.io/client-go => github.com/fork/client-go v0.35.1\n", want: "v0.35.1"},
+		{name: "other version", replace: "replace k8s.io/client-go v0.33.0 => k8s.io/client-go v0.35.0\n", want: "v0.34.0"},
+		{name: "local directory", replace: "replace k8s.io/client-go => ../client-go\n", want: "v0.34.0"},
+		{name: "block", replace: "replace (\n\tk8s.io/api => k8s.io/api v0.35.0\n\tk8s.io/client-go => k8s.io/client-go v0.35.0\n)\n", want: "v0.35.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseK8sClientGoVersion(require + tt.replace)
+			if err != nil {
+				t.Fatalf("parseK8sClientGoVersion() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("parseK8sClientGoVersion() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	clientGo, err := parseK8sClientGoVersion(require + tests[0].replace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := convertClientGoToRealK8sVersion(clientGo); got != "v1.35" {
+		t.Errorf("k8s version = %s, want v1.35 from the replaced client-go", got)
+	}
+}
+
+func TestReportModules(t *testing.T) {
+	var out strings.Builder
+	if err := reportModules(&out, sampleGoMod, []string{"k8s.io/client-go", "sigs.k8s.io/controller-runtime"}); err != nil {
+		t.Fatalf("reportModules() error = %v", err)
+	}
+	want := "k8s.io/client-go v0.35.0\nsigs.k8s.io/controller-runtime v0.21.0\n"
+	if out.String() != want {
+		t.Errorf("reportModules() = %q, want %q", out.String(), want)
+	}
+}
+
+func TestAddReleaseReportModules(t *testing.T) {
+	useFakeTransport(t, sampleGoMod)
+	root := newTestRepo(t)
+	var out strings.Builder
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33", ReportModules: []string{"sigs.k8s.io/controller-runtime"}, ModulesOutput: &out}); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	if got, want := out.String(), "sigs.k8s.io/controller-runtime v0.21.0\n"; got != want {
+		t.Errorf("module versions = %q, want %q", got, want)
+	}
+}
+
+func TestAddReleaseGoModWithoutClientGo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "module example.com/operator\n\ngo 1.23.0\n")
+	}))
+	t.Cleanup(server.Close)
+	original := projects["eso"]
+	t.Cleanup(func() { projects["eso"] = original })
+	projects["eso"] = ProjectDetails{GoModLocation: server.URL + "/%s/go.mod", ProjectLongName: original.ProjectLongName}
+
+	root := newTestRepo(t)
+	_, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0"})
+	if err == nil {
+		t.Fatal("addRelease() expected an error")
+	}
+	for _, want := range []string{"k8s.io/client-go", "v0.15.0", server.URL + "/v0.15.0/go.mod", "--tested-k8s-versions"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestAddReleaseEmitJSON(t *testing.T) {
+	root := newTestRepo(t)
+	if _, err := addRelease(AddOptions{Settings: Settings{EmitJSON: true}, Root: root, Project: "eso", Tag: "v0.15.0", ReleaseDate: "2025-02-01", TestedK8sVersions: "v1.32,v1.33"}); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+
+	fromTOML, err := readVersions(filepath.Join(root, "data", "eso_versions.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join(root, "data", "eso_versions.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON VersionsData
+	if err := json.Unmarshal(content, &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&fromJSON, fromTOML) {
+		t.Errorf("JSON mirror = %+v, want %+v", fromJSON, *fromTOML)
+	}
+	if !strings.Contains(string(content), `"tested_k8s_versions"`) {
+		t.Errorf("JSON mirror does not use the data file field names:\n%s", content)
+	}
+
+	// Once present, the mirror follows later changes even without the option
+	if _, err := removeRelease(Settings{}, root, "eso", "v0.14.0", false); err != nil {
+		t.Fatal(err)
+	}
+	content, err = os.ReadFile(filepath.Join(root, "data", "eso_versions.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "v0.14.0") {
+		t.Errorf("JSON mirror still contains the deleted version:\n%s", content)
+	}
+}
+
+func TestPrintCopyProgress(t *testing.T) {
+	var out strings.Builder
+	progress := printCopyProgress(&out)
+	progress(0, 2)
+	progress(1, 2)
+	progress(2, 2)
+	if want := "\rCopied 0/2 files\rCopied 1/2 files\rCopied 2/2 files\n"; out.String() != want {
+		t.Errorf("progress output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestReadVersionsErrors(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.toml")
+	if err := os.WriteFile(empty, []byte("# no versions yet\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := readVersions(filepath.Join(dir, "missing.toml")); !errors.Is(err, ErrDataFileMissing) {
+		t.Errorf("readVersions(missing) error = %v, want ErrDataFileMissing", err)
+	}
+	if _, err := readVersions(empty); !errors.Is(err, ErrNoVersions) {
+		t.Errorf("readVersions(empty) error = %v, want ErrNoVersions", err)
+	}
+}
+
+func TestAddReleaseNoLatest(t *testing.T) {
+	root := newTestRepo(t)
+	if err := (Settings{}).writeVersions(filepath.Join(root, "data", "eso_versions.toml"), &VersionsData{Versions: []Version{{Tag: "v0.14.0"}}}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33"})
+	if !errors.Is(err, ErrNoLatest) {
+		t.Errorf("addRelease() error = %v, want ErrNoLatest", err)
+	}
+}
+
+func TestAddReleaseFirstVersion(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"data/eso_versions.toml":                  "",
+		"content/en/eso-docs/unreleased/guide.md": "# Guide\n",
+	})
+
+	changes, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.1.0", ReleaseDate: "2025-01-01", TestedK8sVersions: "v1.33"})
+	if err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	if changes.PreviousLatest != "" {
+		t.Errorf("PreviousLatest = %q, want none", changes.PreviousLatest)
+	}
+
+	versions, err := readVersions(filepath.Join(root, "data", "eso_versions.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Version{{Tag: "v0.1.0", Latest: true, ReleaseDate: "2025-01-01", TestedK8sVersions: []string{"v1.33"}}}
+	if !reflect.DeepEqual(versions.Versions, want) {
+		t.Errorf("versions = %+v, want %+v", versions.Versions, want)
+	}
+
+	baseDir := filepath.Join(root, "content", "en", "eso-docs")
+	landing, err := os.ReadFile(filepath.Join(baseDir, "v0.1", "_index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(landing), `project_version = "v0.1"`) {
+		t.Errorf("landing page is not for v0.1:\n%s", landing)
+	}
+	if _, err := os.Stat(filepath.Join(baseDir, "v0.1", "guide.md")); err != nil {
+		t.Errorf("content was not copied: %v", err)
+	}
+	rootIndex, err := os.ReadFile(filepath.Join(baseDir, "_index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(rootIndex), `type = "redirect"`) {
+		t.Errorf("root index is not a redirect:\n%s", rootIndex)
+	}
+}
+
+func TestAddReleaseEmptySource(t *testing.T) {
+	root := newTestRepo(t)
+	unreleased := filepath.Join(root, "content", "en", "eso-docs", "unreleased")
+	if err := os.RemoveAll(unreleased); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(unreleased, "guide"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	opts := AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33", RequireContent: true}
+
+	_, err := addRelease(opts)
+	if err == nil || !strings.Contains(err.Error(), "has no files") {
+		t.Fatalf("addRelease() with RequireContent error = %v, want no files", err)
+	}
+
+	warnings := &warningList{}
+	opts.RequireContent = false
+	opts.Warnings = warnings
+	if _, err := addRelease(opts); err != nil {
+		t.Fatalf("addRelease() error = %v, want a warning only", err)
+	}
+	if got := warnings.list(); len(got) != 1 || !strings.Contains(got[0], "has no files") {
+		t.Errorf("warnings = %q, want the empty content source", got)
+	}
+}
+
+func TestAddReleaseCopyExcludeDir(t *testing.T) {
+	root := newTestRepo(t)
+	unreleased := filepath.Join(root, "content", "en", "eso-docs", "unreleased")
+	if err := os.MkdirAll(filepath.Join(unreleased, "examples", "nested"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(unreleased, "examples", "nested", "x.md"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33", CopyExcludeDirs: []string{"examples/"}}
+
+	if _, err := addRelease(opts); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	newVersionDir := filepath.Join(root, "content", "en", "eso-docs", "v0.15")
+	if _, err := os.Stat(filepath.Join(newVersionDir, "examples")); !os.IsNotExist(err) {
+		t.Errorf("examples was copied, stat error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(newVersionDir, "guide", "page.md")); err != nil {
+		t.Errorf("guide/page.md was not copied: %v", err)
+	}
+}
+
+func TestAddReleaseCopyExcludeDirOutsideSource(t *testing.T) {
+	for _, dir := range []string{"..", "../other", "/abs", "."} {
+		opts := AddOptions{Root: newTestRepo(t), Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33", CopyExcludeDirs: []string{dir}}
+		if _, err := addRelease(opts); !errors.Is(err, ErrInvalid) {
+			t.Errorf("addRelease() with --copy-exclude-dir %q error = %v, want ErrInvalid", dir, err)
+		}
+	}
+}
+
+func TestAddReleaseTagPrefix(t *testing.T) {
+	original := projects["eso"]
+	t.Cleanup(func() { projects["eso"] = original })
+	details := original
+	details.TagPrefix = "eso/"
+	projects["eso"] = details
+	fake := useFakeTransport(t, sampleGoMod)
+
+	root := newTestRepo(t)
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "eso/v0.15.0"}); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	if len(fake.requested) != 1 || !strings.Contains(fake.requested[0], "/eso/v0.15.0/go.mod") {
+		t.Errorf("requested %v, want the go.mod of the prefixed tag", fake.requested)
+	}
+	if _, err := os.Stat(filepath.Join(root, "content", "en", "eso-docs", "v0.15", "_index.md")); err != nil {
+		t.Errorf("the content folder does not use the bare version: %v", err)
+	}
+	versions, err := readVersions(Settings{}.dataFilePath(root, "eso"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idx, err := latestIndex(versions.Versions); err != nil || versions.Versions[idx].Tag != "v0.15.0" {
+		t.Errorf("latest version = %+v (%v), want the bare v0.15.0", versions.Versions, err)
+	}
+}
+
+func TestAddReleaseDisplayVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		opts AddOptions
+		want string
+	}{
+		{name: "default", want: ""},
+		{name: "override", opts: AddOptions{DisplayVersion: "v0.15 (LTS)"}, want: "v0.15 (LTS)"},
+		{name: "canonical", opts: AddOptions{CanonicalVersion: true}, want: "v0.15"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := newTestRepo(t)
+			opts := tt.opts
+			opts.Root, opts.Project, opts.Tag, opts.TestedK8sVersions = root, "eso", "v0.15.3", "v1.33"
+			if _, err := addRelease(opts); err != nil {
+				t.Fatalf("addRelease() error = %v", err)
+			}
+
+			dataFile := filepath.Join(root, "data", "eso_versions.toml")
+			versions, err := readVersions(dataFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := versions.Versions[0]
+			if got.Tag != "v0.15.3" || got.Version != tt.want {
+				t.Errorf("version = %+v, want tag v0.15.3 and version %q", got, tt.want)
+			}
+			if majorMinor, _ := extractMajorMinor(got.Tag); (Settings{}).docsURL("eso", majorMinor) != "/eso-docs/v0.15/" {
+				t.Errorf("docsURL() = %s, want /eso-docs/v0.15/", Settings{}.docsURL("eso", majorMinor))
+			}
+			if tt.want == "" {
+				data, err := os.ReadFile(dataFile)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if strings.Contains(string(data), "version =") {
+					t.Errorf("data file has a version without override:\n%s", data)
+				}
+			}
+		})
+	}
+}
+
+func TestAddReleaseLatestLabel(t *testing.T) {
+	root := newTestRepo(t)
+	dataFile := Settings{}.dataFilePath(root, "eso")
+	if err := (Settings{}).writeVersions(dataFile, &VersionsData{Versions: []Version{
+		{Tag: "v0.14.0", Latest: true, ReleaseDate: "2025-01-01", Version: "v0.14 (latest)"},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33", DisplayVersion: "v0.15 [current]", LatestLabel: "[current]"}
+	if _, err := addRelease(opts); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	opts = AddOptions{Root: root, Project: "eso", Tag: "v0.16.0", TestedK8sVersions: "v1.33", DisplayVersion: "v0.16"}
+	if _, err := addRelease(opts); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+
+	versions, err := readVersions(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if versions.LatestLabel != "[current]" {
+		t.Errorf("latest_label = %q, want [current]", versions.LatestLabel)
+	}
+	want := map[string]string{"v0.16.0": "v0.16", "v0.15.0": "v0.15", "v0.14.0": "v0.14"}
+	for _, v := range versions.Versions {
+		if v.Version != want[v.Tag] {
+			t.Errorf("%s version = %q, want %q without any label", v.Tag, v.Version, want[v.Tag])
+		}
+	}
+
+	opts = AddOptions{Root: root, Project: "eso", Tag: "v0.17.0", TestedK8sVersions: "v1.33", LatestLabel: defaultLatestLabel}
+	if _, err := addRelease(opts); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	if versions, err = readVersions(dataFile); err != nil {
+		t.Fatal(err)
+	}
+	if versions.LatestLabel != "" {
+		t.Errorf("latest_label = %q, want the default label left out of the data file", versions.LatestLabel)
+	}
+}
+
+func TestAddReleaseNoDemote(t *testing.T) {
+	root := newTestRepo(t)
+	dataFile := Settings{}.dataFilePath(root, "eso")
+	previous := Version{Tag: "v0.14.0", Latest: true, ReleaseDate: "2025-01-01", TestedK8sVersions: []string{"v1.32"}, Version: "v0.14 (latest)"}
+	if err := (Settings{}).writeVersions(dataFile, &VersionsData{Versions: []Version{previous}}); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33", NoDemote: true})
+	if err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	versions, err := readVersions(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions.Versions) != 2 || versions.Versions[0].Tag != "v0.15.0" || !versions.Versions[0].Latest {
+		t.Fatalf("versions = %+v, want v0.15.0 added as latest", versions.Versions)
+	}
+	if !reflect.DeepEqual(versions.Versions[1], previous) {
+		t.Errorf("previous latest = %+v, want it untouched %+v", versions.Versions[1], previous)
+	}
+	if !changes.PreviousKeptLatest || !strings.Contains(changes.Markdown(), "not demoted") {
+		t.Errorf("summary = %q, want the previous latest reported as not demoted", changes.Markdown())
+	}
+}
+
+func TestAddReleaseReleaseDateOrder(t *testing.T) {
+	tests := []struct {
+		name         string
+		releaseDate  string
+		strict       bool
+		wantWarnings int
+		wantErr      bool
+	}{
+		{name: "later", releaseDate: "2025-02-01"},
+		{name: "same day", releaseDate: "2025-01-01"},
+		{name: "earlier", releaseDate: "2024-12-01", wantWarnings: 1},
+		{name: "earlier strict", releaseDate: "2024-12-01", strict: true, wantErr: true},
+		{name: "unparseable", releaseDate: "01/12/2024", strict: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := &warningList{}
+			opts := AddOptions{Root: newTestRepo(t), Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33", ReleaseDate: tt.releaseDate, StrictDates: tt.strict, Warnings: warnings}
+			_, err := addRelease(opts)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), "earlier than 2025-01-01") {
+					t.Fatalf("addRelease() error = %v, want an earlier release date error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("addRelease() error = %v", err)
+			}
+			if got := warnings.list(); len(got) != tt.wantWarnings {
+				t.Errorf("warnings = %q, want %d", got, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestCheckReleaseDateOrderPreviousWithoutDate(t *testing.T) {
+	for _, previous := range []string{"", "soon"} {
+		if err := checkReleaseDateOrder(Version{Tag: "v0.14.0", ReleaseDate: previous}, "2020-01-01"); err != nil {
+			t.Errorf("checkReleaseDateOrder() with previous date %q error = %v, want the check skipped", previous, err)
+		}
+	}
+}
+
+func TestAddReleaseDisplayVersionConflict(t *testing.T) {
+	root := newTestRepo(t)
+	_, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.3", TestedK8sVersions: "v1.33", DisplayVersion: "v0.15", CanonicalVersion: true})
+	if err == nil || !strings.Contains(err.Error(), "mutually exclusive") {
+		t.Errorf("addRelease() error = %v, want mutually exclusive flags", err)
+	}
+}
+
+func TestAddReleaseNoCopy(t *testing.T) {
+	root := newTestRepo(t)
+
+	changes, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33", NoCopy: true})
+	if err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "content", "en", "eso-docs", "v0.15")); !os.IsNotExist(err) {
+		t.Errorf("version directory was created with NoCopy: %v", err)
+	}
+	if want := []string{"data/eso_versions.toml"}; !reflect.DeepEqual(changes.FilesModified, want) || len(changes.FilesCreated) != 0 {
+		t.Errorf("changes = %+v, want only %v modified", changes, want)
+	}
+
+	versions, err := readVersions(filepath.Join(root, "data", "eso_versions.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := versions.Versions[0]; got.Tag != "v0.15.0" || !got.Latest {
+		t.Errorf("first version = %+v, want v0.15.0 as latest", got)
+	}
+}
+
+func TestAddReleaseDryRunDiff(t *testing.T) {
+	root := newTestRepo(t)
+	dataFile := filepath.Join(root, "data", "eso_versions.toml")
+	before, err := os.ReadFile(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	_, err = addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", ReleaseDate: "2025-02-01", TestedK8sVersions: "v1.33", DryRun: true, DiffOutput: &out})
+	if err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"--- a/data/eso_versions.toml\n+++ b/data/eso_versions.toml\n",
+		`+  tag = "v0.15.0"`,
+		"+  latest = false\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("diff does not contain %q:\n%s", want, out.String())
+		}
+	}
+	after, err := os.ReadFile(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(before) {
+		t.Errorf("dry run changed the data file:\n%s", after)
+	}
+}
+
+func TestAddReleaseRejectsImplausibleK8sVersions(t *testing.T) {
+	root := newTestRepo(t)
+	_, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33,v1.350", MinK8sMinor: defaultMinK8sMinor})
+	if err == nil || !strings.Contains(err.Error(), "v1.350") {
+		t.Errorf("addRelease() error = %v, want v1.350 to be rejected", err)
+	}
+}
+
+func TestDirIsEmpty(t *testing.T) {
+	dir := t.TempDir()
+	for _, tt := range []struct {
+		path string
+		want bool
+	}{
+		{path: filepath.Join(dir, "missing"), want: true},
+		{path: dir, want: true},
+	} {
+		got, err := dirIsEmpty(tt.path)
+		if err != nil || got != tt.want {
+			t.Errorf("dirIsEmpty(%s) = %v, %v, want %v", tt.path, got, err, tt.want)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "file"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := dirIsEmpty(dir); err != nil || got {
+		t.Errorf("dirIsEmpty() = %v, %v, want false", got, err)
+	}
+}
+
+func TestAddReleaseExistingVersionDir(t *testing.T) {
+	t.Run("empty folder", func(t *testing.T) {
+		root := newTestRepo(t)
+		if err := os.MkdirAll(filepath.Join(root, "content", "en", "eso-docs", "v0.15"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33"}); err != nil {
+			t.Errorf("addRelease() error = %v", err)
+		}
+	})
+
+	t.Run("non empty folder requires force", func(t *testing.T) {
+		root := newTestRepo(t)
+		stale := filepath.Join(root, "content", "en", "eso-docs", "v0.15", "stale.md")
+		if err := os.MkdirAll(filepath.Dir(stale), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(stale, []byte("# Stale\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		opts := AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33"}
+
+		_, err := addRelease(opts)
+		if err == nil || !strings.Contains(err.Error(), "--force") {
+			t.Fatalf("addRelease() error = %v, want --force to be required", err)
+		}
+		versions, err := readVersions(filepath.Join(root, "data", "eso_versions.toml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(versions.Versions) != 1 {
+			t.Errorf("data file was changed: %+v", versions.Versions)
+		}
+
+		opts.Force = true
+		if _, err := addRelease(opts); err != nil {
+			t.Errorf("addRelease() with Force error = %v", err)
+		}
+	})
+
+	t.Run("patch release of an existing major.minor", func(t *testing.T) {
+		root := newTestRepo(t)
+		if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33"}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.1", TestedK8sVersions: "v1.33"}); err != nil {
+			t.Errorf("addRelease() error = %v", err)
+		}
+	})
+}
+
+func TestAddReleaseDataFileHeader(t *testing.T) {
+	root := newTestRepo(t)
+	dataFile := filepath.Join(root, "data", "eso_versions.toml")
+	header := "project_long_name = \"ESO\"\ndefault_eol_months = 6\n\n"
+	content, err := os.ReadFile(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dataFile, append([]byte(header), content...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(root, "content", "en", "eso-docs", "unreleased", "_index.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", ReleaseDate: "2025-02-01", TestedK8sVersions: "v1.33"}); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+
+	versions, err := readVersions(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if versions.ProjectLongName != "ESO" || versions.DefaultEOLMonths != 6 {
+		t.Errorf("header = %q, %d, want it kept", versions.ProjectLongName, versions.DefaultEOLMonths)
+	}
+	if got := versions.Versions[0].EndOfLife; got != "2025-08-01" {
+		t.Errorf("EndOfLife = %q, want 2025-08-01", got)
+	}
+	landing, err := os.ReadFile(filepath.Join(root, "content", "en", "eso-docs", "v0.15", "_index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(landing), `title = "ESO v0.15 Documentation"`) {
+		t.Errorf("landing page does not use the long name of the header:\n%s", landing)
+	}
+}
+
+func TestNormalizeVersion(t *testing.T) {
+	for input, want := range map[string]string{
+		"0.15":       "v0.15",
+		"v0.15":      "v0.15",
+		"0.15.0":     "v0.15.0",
+		" v1.2.3 ":   "v1.2.3",
+		"unreleased": "unreleased",
+	} {
+		if got := normalizeVersion(input); got != want {
+			t.Errorf("normalizeVersion(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v0.15", "v0.15.0", 0},
+		{"0.15.0", "v0.15", 0},
+		{"v0.9", "0.10.0", -1},
+		{"v0.15.1", "v0.15", 1},
+		{"v0.15.0-rc.1", "0.15.0", -1},
+		{"unreleased", "v0.1.0", -1},
+		{"abc", "abd", -1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestAddReleaseMixedPrecision(t *testing.T) {
+	root := newTestRepo(t)
+
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "0.15", TestedK8sVersions: "v1.33"}); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "content", "en", "eso-docs", "v0.15")); err != nil {
+		t.Errorf("version folder is not v0.15: %v", err)
+	}
+	_, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33"})
+	if err == nil || !strings.Contains(err.Error(), "already exists (as v0.15)") {
+		t.Errorf("addRelease() error = %v, want v0.15.0 to duplicate v0.15", err)
+	}
+
+	versions, err := readVersions(filepath.Join(root, "data", "eso_versions.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := versions.Versions[0].Tag; got != "v0.15" {
+		t.Errorf("stored tag = %q, want v0.15", got)
+	}
+	if got := versionFolders(versions.Versions); !reflect.DeepEqual(got, []string{"v0.15", "v0.14"}) {
+		t.Errorf("versionFolders() = %v, want [v0.15 v0.14]", got)
+	}
+}
+
+func TestAddReleaseNoIndexUpdate(t *testing.T) {
+	root := newTestRepo(t)
+	rootIndex := filepath.Join(root, "content", "en", "eso-docs", "_index.md")
+	custom := "+++\ntitle = \"ESO\"\n+++\n\n{{< latest-version project=\"eso\" >}}\r\n"
+	if err := os.WriteFile(rootIndex, []byte(custom), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33", NoIndexUpdate: true}); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	got, err := os.ReadFile(rootIndex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != custom {
+		t.Errorf("root index changed:\n%q\nwant\n%q", got, custom)
+	}
+
+	if err := os.Remove(rootIndex); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.16.0", TestedK8sVersions: "v1.33", NoIndexUpdate: true}); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	if _, err := os.Stat(rootIndex); !os.IsNotExist(err) {
+		t.Errorf("root index was created with NoIndexUpdate: %v", err)
+	}
+}
+
+func TestAddReleaseKeepsDataFileCRLF(t *testing.T) {
+	root := newTestRepo(t)
+	dataFile := filepath.Join(root, "data", "eso_versions.toml")
+	content, err := os.ReadFile(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := strings.ReplaceAll(string(content), "\n", "\r\n")
+	if err := os.WriteFile(dataFile, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", ReleaseDate: "2025-02-01", TestedK8sVersions: "v1.33"}); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+
+	got, err := os.ReadFile(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(got)
+	if strings.Count(text, "\n") != strings.Count(text, "\r\n") {
+		t.Errorf("data file mixes line endings:\n%q", text)
+	}
+	want := "[[versions]]\r\n  tag = \"v0.15.0\"\r\n  latest = true\r\n  release_date = \"2025-02-01\"\r\n  tested_k8s_versions = [\"v1.33\"]\r\n  end_of_life = \"\"\r\n\r\n" +
+		strings.Replace(original, "latest = true", "latest = false", 1)
+	if text != want {
+		t.Errorf("data file =\n%q\nwant\n%q", text, want)
+	}
+}
+
+func TestAddReleaseGeneratedModes(t *testing.T) {
+	root := newTestRepo(t)
+	page := filepath.Join(root, "content", "en", "eso-docs", "unreleased", "guide", "page.md")
+	if err := os.Chmod(page, 0600); err != nil {
+		t.Fatal(err)
+	}
+	settings := backupSettings(false)
+	settings.FileMode, settings.DirMode = 0664, 0775
+	settings.ArtifactsDir = filepath.Join(t.TempDir(), "artifacts")
+	settings.EmitJSON = true
+	planFile, err := settings.createArtifact(planArtifact)
+	if err != nil {
+		t.Fatalf("createArtifact() error = %v", err)
+	}
+	defer planFile.Close()
+
+	if _, err := addRelease(AddOptions{Settings: settings, Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33", Manifest: true, PlanOutput: planFile}); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	versionDir := filepath.Join(root, "content", "en", "eso-docs", "v0.15")
+	for path, want := range map[string]fs.FileMode{
+		versionDir:                                                    fs.ModeDir | 0775,
+		filepath.Join(versionDir, "_index.md"):                        0664,
+		filepath.Join(versionDir, manifestFile):                       0664,
+		filepath.Join(root, "data", "eso_versions.toml"):              0664,
+		filepath.Join(root, "data", "eso_versions.toml"+backupSuffix): 0664,
+		// The artifacts of --artifacts-dir
+		settings.ArtifactsDir:                                     fs.ModeDir | 0775,
+		filepath.Join(settings.ArtifactsDir, planArtifact):        0664,
+		filepath.Join(settings.ArtifactsDir, "eso_versions.json"): 0664,
+		// Copied files keep the mode of their source
+		filepath.Join(versionDir, "guide", "page.md"): 0600,
+	} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode(); got != want {
+			t.Errorf("mode of %s = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// allProjects is the --project value selecting every configured project
+const allProjects = "all"
+
+func handleSetTestedK8sVersions(settings Settings, project string, testedK8sVersions string, minK8sMinor int, maxK8sMinor int) {
+	if project == "" || testedK8sVersions == "" {
+		fmt.Print("Missing project or tested k8s versions\n")
+		printReleaseUsage()
+		os.Exit(1)
+	}
+
+	names, err := selectProjects(project)
+	if err != nil {
+		exitWithError(err)
+	}
+	tested := splitK8sVersions(testedK8sVersions)
+	if len(tested) == 0 {
+		exitWithError(invalidf("--tested-k8s-versions %q has no version", testedK8sVersions))
+	}
+	if err := validateTestedK8sVersions(tested, minK8sMinor, maxK8sMinor); err != nil {
+		exitWithError(err)
+	}
+
+	if err := setLatestTestedK8sVersions(settings, "", names, tested); err != nil {
+		exitWithError(err)
+	}
+}
+
+// selectProjects returns the project names matching a --project value,
+// expanding "all" to every configured project in a stable order.
+func selectProjects(project string) ([]string, error) {
+	if project == allProjects {
+		return projectNames(), nil
+	}
+	if err := validateProject(project); err != nil {
+		return nil, fmt.Errorf("%w (or '%s')", err, allProjects)
+	}
+	return []string{project}, nil
+}
+
+// setLatestTestedK8sVersions replaces the tested k8s versions of the latest
+// entry of each project, without adding any version.
+// All data files are validated before any of them is rewritten.
+func setLatestTestedK8sVersions(settings Settings, root string, names []string, testedK8sVersions []string) error {
+	type update struct {
+		dataFile string
+		versions *VersionsData
+	}
+	var updates []update
+
+	for _, name := range names {
+		dataFile := settings.dataFilePath(root, name)
+		unlock, err := lockDataFile(dataFile)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		versions, err := readVersions(dataFile)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", dataFile, err)
+		}
+		latestIdx, err := latestIndex(versions.Versions)
+		if err != nil {
+			return fmt.Errorf("%w in %s", err, dataFile)
+		}
+		versions.Versions[latestIdx].TestedK8sVersions = testedK8sVersions
+		updates = append(updates, update{dataFile: dataFile, versions: versions})
+	}
+
+	for _, u := range updates {
+		if err := settings.writeVersions(u.dataFile, u.versions); err != nil {
+			return err
+		}
+		printStep("Updated %s", u.dataFile)
+	}
+	return nil
+}
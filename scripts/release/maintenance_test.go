@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSetLatestTestedK8sVersionsAllProjects(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "data"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"eso", "reloader"} {
+		if err := (Settings{}).writeVersions(filepath.Join(root, "data", name+"_versions.toml"), &VersionsData{Versions: []Version{
+			{Tag: "v1.1.0", Latest: true, TestedK8sVersions: []string{"v1.30"}},
+			{Tag: "v1.0.0", TestedK8sVersions: []string{"v1.29"}},
+		}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	names, err := selectProjects(allProjects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := setLatestTestedK8sVersions(Settings{}, root, names, []string{"v1.35", "v1.36"}); err != nil {
+		t.Fatalf("setLatestTestedK8sVersions() error = %v", err)
+	}
+
+	for _, name := range []string{"eso", "reloader"} {
+		versions, err := readVersions(filepath.Join(root, "data", name+"_versions.toml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := versions.Versions[0].TestedK8sVersions; !reflect.DeepEqual(got, []string{"v1.35", "v1.36"}) {
+			t.Errorf("%s latest tested versions = %v", name, got)
+		}
+		if got := versions.Versions[1].TestedK8sVersions; !reflect.DeepEqual(got, []string{"v1.29"}) {
+			t.Errorf("%s older tested versions changed to %v", name, got)
+		}
+	}
+}
+
+func TestSelectProjectsUnknown(t *testing.T) {
+	if _, err := selectProjects("unknown"); err == nil {
+		t.Error("selectProjects() expected an error for an unknown project")
+	}
+}
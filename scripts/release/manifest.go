@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"path/filepath"
+)
+
+// manifestFile names the checksum manifest of a version folder
+const manifestFile = "manifest.json"
+
+// ManifestEntry is the checksum of a file of a version folder
+type ManifestEntry struct {
+	// Path is relative to the version folder, with forward slashes
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+// Manifest lists the files of a version folder with their checksum, for
+// integrity checks and cache busting
+type Manifest struct {
+	Files []ManifestEntry `json:"files"`
+}
+
+// buildManifest hashes the regular files below dir, in lexical order,
+// except a previous manifest. Symlinks are not followed.
+func buildManifest(dir string) (*Manifest, error) {
+	manifest := &Manifest{Files: []ManifestEntry{}}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if rel == manifestFile {
+			return nil
+		}
+		sum, err := hashFile(osFS{}, path)
+		if err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, ManifestEntry{Path: filepath.ToSlash(rel), SHA256: hex.EncodeToString(sum)})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// writeManifest writes the manifest of dir as dir/manifest.json and
+// returns its path
+func (s Settings) writeManifest(dir string) (string, error) {
+	manifest, err := buildManifest(dir)
+	if err != nil {
+		return "", err
+	}
+	out, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, manifestFile)
+	return path, s.writeGeneratedFile(path, append(out, '\n'))
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestAddReleaseManifest(t *testing.T) {
+	root := newTestRepo(t)
+	changes, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33", Manifest: true})
+	if err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	newVersionDir := filepath.Join(root, "content", "en", "eso-docs", "v0.15")
+
+	content, err := os.ReadFile(filepath.Join(newVersionDir, manifestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		t.Fatalf("invalid manifest %s: %v", content, err)
+	}
+	var paths []string
+	for _, entry := range manifest.Files {
+		paths = append(paths, entry.Path)
+		file, err := os.ReadFile(filepath.Join(newVersionDir, filepath.FromSlash(entry.Path)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sum := sha256.Sum256(file); entry.SHA256 != hex.EncodeToString(sum[:]) {
+			t.Errorf("%s sha256 = %s, want %x", entry.Path, entry.SHA256, sum)
+		}
+	}
+	if want := []string{"_index.md", "guide/page.md"}; !slices.Equal(paths, want) {
+		t.Errorf("manifest paths = %v, want %v without the manifest itself", paths, want)
+	}
+	if !slices.Contains(changes.FilesCreated, "content/en/eso-docs/v0.15/"+manifestFile) {
+		t.Errorf("files created = %v, want the manifest", changes.FilesCreated)
+	}
+}
+
+func TestBuildManifestSkipsPreviousManifest(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, manifestFile), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := buildManifest(dir)
+	if err != nil {
+		t.Fatalf("buildManifest() error = %v", err)
+	}
+	if len(manifest.Files) != 0 {
+		t.Errorf("buildManifest() = %+v, want no files", manifest.Files)
+	}
+}
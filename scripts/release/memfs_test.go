@@ -0,0 +1,316 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memFS is an in-memory FS for tests
+type memFS struct {
+	nodes map[string]*memNode
+}
+
+type memNode struct {
+	mode    fs.FileMode
+	data    []byte
+	target  string
+	modTime time.Time
+}
+
+type memFileInfo struct {
+	name string
+	node *memNode
+}
+
+func (i memFileInfo) Name() string       { return i.name }
+func (i memFileInfo) Size() int64        { return int64(len(i.node.data)) }
+func (i memFileInfo) Mode() fs.FileMode  { return i.node.mode }
+func (i memFileInfo) ModTime() time.Time { return i.node.modTime }
+func (i memFileInfo) IsDir() bool        { return i.node.mode.IsDir() }
+func (i memFileInfo) Sys() any           { return nil }
+
+func newMemFS() *memFS {
+	return &memFS{nodes: map[string]*memNode{"/": {mode: fs.ModeDir | 0755}}}
+}
+
+func (m *memFS) lookup(op, name string) (*memNode, error) {
+	node, ok := m.nodes[filepath.Clean(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return node, nil
+}
+
+func (m *memFS) checkParent(op, name string) error {
+	parent, err := m.lookup(op, filepath.Dir(filepath.Clean(name)))
+	if err != nil {
+		return err
+	}
+	if !parent.mode.IsDir() {
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return nil
+}
+
+func (m *memFS) Lstat(name string) (fs.FileInfo, error) {
+	node, err := m.lookup("lstat", name)
+	if err != nil {
+		return nil, err
+	}
+	return memFileInfo{name: filepath.Base(name), node: node}, nil
+}
+
+func (m *memFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	name = filepath.Clean(name)
+	if _, err := m.lookup("readdir", name); err != nil {
+		return nil, err
+	}
+	var entries []fs.DirEntry
+	for path, node := range m.nodes {
+		if path != name && filepath.Dir(path) == name {
+			entries = append(entries, fs.FileInfoToDirEntry(memFileInfo{name: filepath.Base(path), node: node}))
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+func (m *memFS) Open(name string) (io.ReadCloser, error) {
+	node, err := m.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(node.data)), nil
+}
+
+type memWriter struct {
+	bytes.Buffer
+	node *memNode
+}
+
+func (w *memWriter) Close() error {
+	w.node.data = append([]byte(nil), w.Bytes()...)
+	return nil
+}
+
+func (m *memFS) Create(name string, mode fs.FileMode) (io.WriteCloser, error) {
+	if err := m.checkParent("create", name); err != nil {
+		return nil, err
+	}
+	node := &memNode{mode: mode.Perm(), modTime: time.Now()}
+	m.nodes[filepath.Clean(name)] = node
+	return &memWriter{node: node}, nil
+}
+
+func (m *memFS) MkdirAll(path string, mode fs.FileMode) error {
+	path = filepath.Clean(path)
+	if node, ok := m.nodes[path]; ok {
+		if !node.mode.IsDir() {
+			return &fs.PathError{Op: "mkdir", Path: path, Err: fs.ErrExist}
+		}
+		return nil
+	}
+	if err := m.MkdirAll(filepath.Dir(path), mode); err != nil {
+		return err
+	}
+	m.nodes[path] = &memNode{mode: fs.ModeDir | mode.Perm(), modTime: time.Now()}
+	return nil
+}
+
+func (m *memFS) Chmod(name string, mode fs.FileMode) error {
+	node, err := m.lookup("chmod", name)
+	if err != nil {
+		return err
+	}
+	node.mode = node.mode.Type() | mode.Perm()
+	return nil
+}
+
+func (m *memFS) Chtimes(name string, atime, mtime time.Time) error {
+	node, err := m.lookup("chtimes", name)
+	if err != nil {
+		return err
+	}
+	node.modTime = mtime
+	return nil
+}
+
+func (m *memFS) Readlink(name string) (string, error) {
+	node, err := m.lookup("readlink", name)
+	if err != nil {
+		return "", err
+	}
+	if node.mode&fs.ModeSymlink == 0 {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+	}
+	return node.target, nil
+}
+
+func (m *memFS) Symlink(oldname, newname string) error {
+	if err := m.checkParent("symlink", newname); err != nil {
+		return err
+	}
+	if _, ok := m.nodes[filepath.Clean(newname)]; ok {
+		return &fs.PathError{Op: "symlink", Path: newname, Err: fs.ErrExist}
+	}
+	m.nodes[filepath.Clean(newname)] = &memNode{mode: fs.ModeSymlink | 0777, target: oldname, modTime: time.Now()}
+	return nil
+}
+
+func (m *memFS) Remove(name string) error {
+	name = filepath.Clean(name)
+	if _, err := m.lookup("remove", name); err != nil {
+		return err
+	}
+	for path := range m.nodes {
+		if strings.HasPrefix(path, name+string(filepath.Separator)) {
+			return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrInvalid}
+		}
+	}
+	delete(m.nodes, name)
+	return nil
+}
+
+// writeFile adds a regular file to the fake, creating its parents
+func (m *memFS) writeFile(t *testing.T, name string, content string, mode fs.FileMode, modTime time.Time) {
+	t.Helper()
+	if err := m.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		t.Fatal(err)
+	}
+	m.nodes[filepath.Clean(name)] = &memNode{mode: mode, data: []byte(content), modTime: modTime}
+}
+
+func TestCopyDirInMemory(t *testing.T) {
+	m := newMemFS()
+	modTime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	m.writeFile(t, "/src/_index.md", "index", 0644, modTime)
+	m.writeFile(t, "/src/guide/run.sh", "#!/bin/sh", 0755, modTime)
+	if err := m.Symlink("../_index.md", "/src/guide/alias.md"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CopyDirWithOptions("/src", "/dst", CopyOptions{FS: m}); err != nil {
+		t.Fatalf("CopyDirWithOptions() error = %v", err)
+	}
+
+	index, err := m.lookup("test", "/dst/_index.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(index.data) != "index" || !index.modTime.Equal(modTime) {
+		t.Errorf("_index.md = %q (mtime %v), want %q (mtime %v)", index.data, index.modTime, "index", modTime)
+	}
+	script, err := m.lookup("test", "/dst/guide/run.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if script.mode.Perm() != 0755 {
+		t.Errorf("run.sh mode = %v, want 0755", script.mode.Perm())
+	}
+	target, err := m.Readlink("/dst/guide/alias.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target != "../_index.md" {
+		t.Errorf("alias.md target = %q, want %q", target, "../_index.md")
+	}
+}
+
+func TestCopyDirInMemoryStrictSymlink(t *testing.T) {
+	m := newMemFS()
+	m.writeFile(t, "/src/page.md", "page", 0644, time.Now())
+	if err := m.Symlink("../../etc/passwd", "/src/leak"); err != nil {
+		t.Fatal(err)
+	}
+
+	err := CopyDirWithOptions("/src", "/dst", CopyOptions{FS: m, EscapingSymlinks: SymlinkStrict})
+	if err == nil || !strings.Contains(err.Error(), "escapes source") {
+		t.Fatalf("CopyDirWithOptions() error = %v, want escaping symlink error", err)
+	}
+}
+
+// creationRecorder records the files created through an FS
+type creationRecorder struct {
+	*memFS
+	created []string
+}
+
+func (r *creationRecorder) Create(name string, mode fs.FileMode) (io.WriteCloser, error) {
+	r.created = append(r.created, name)
+	return r.memFS.Create(name, mode)
+}
+
+func TestCopyDirSkipUnchanged(t *testing.T) {
+	m := newMemFS()
+	modTime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	m.writeFile(t, "/src/same.md", "same", 0644, modTime)
+	m.writeFile(t, "/src/edited.md", "new content", 0644, modTime.Add(time.Hour))
+	m.writeFile(t, "/src/resized.md", "longer content", 0644, modTime)
+	m.writeFile(t, "/src/added.md", "added", 0644, modTime)
+	m.writeFile(t, "/dst/same.md", "same", 0644, modTime)
+	m.writeFile(t, "/dst/edited.md", "old content", 0644, modTime)
+	m.writeFile(t, "/dst/resized.md", "short", 0644, modTime)
+
+	rec := &creationRecorder{memFS: m}
+	if err := CopyDirWithOptions("/src", "/dst", CopyOptions{FS: rec, SkipUnchanged: true}); err != nil {
+		t.Fatalf("CopyDirWithOptions() error = %v", err)
+	}
+
+	sort.Strings(rec.created)
+	if want := []string{"/dst/added.md", "/dst/edited.md", "/dst/resized.md"}; !reflect.DeepEqual(rec.created, want) {
+		t.Errorf("rewritten files = %v, want %v", rec.created, want)
+	}
+	same, err := m.lookup("test", "/dst/same.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !same.modTime.Equal(modTime) {
+		t.Errorf("same.md mtime = %v, want %v", same.modTime, modTime)
+	}
+}
+
+func TestCopyDirSkipUnchangedCompareContent(t *testing.T) {
+	m := newMemFS()
+	modTime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	m.writeFile(t, "/src/page.md", "aaaa", 0644, modTime)
+	m.writeFile(t, "/dst/page.md", "bbbb", 0644, modTime)
+
+	if err := CopyDirWithOptions("/src", "/dst", CopyOptions{FS: m, SkipUnchanged: true, CompareContent: true}); err != nil {
+		t.Fatalf("CopyDirWithOptions() error = %v", err)
+	}
+	page, err := m.lookup("test", "/dst/page.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(page.data) != "aaaa" {
+		t.Errorf("page.md = %q, want it rewritten despite identical size and mtime", page.data)
+	}
+}
+
+func TestCopyDirProgress(t *testing.T) {
+	m := newMemFS()
+	m.writeFile(t, "/src/_index.md", "index", 0644, time.Now())
+	m.writeFile(t, "/src/a/one.md", "one", 0644, time.Now())
+	m.writeFile(t, "/src/a/b/two.md", "two", 0644, time.Now())
+	if err := m.Symlink("one.md", "/src/a/alias.md"); err != nil {
+		t.Fatal(err)
+	}
+
+	var calls [][2]int
+	progress := func(copied, total int) { calls = append(calls, [2]int{copied, total}) }
+	if err := CopyDirWithOptions("/src", "/dst", CopyOptions{FS: m, Progress: progress}); err != nil {
+		t.Fatalf("CopyDirWithOptions() error = %v", err)
+	}
+
+	want := [][2]int{{0, 4}, {1, 4}, {2, 4}, {3, 4}, {4, 4}}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("progress calls = %v, want %v", calls, want)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ResolvedPaths are the paths add computes for a project and a version
+type ResolvedPaths struct {
+	BaseDir          string `json:"base_dir"`
+	DataFile         string `json:"data_file"`
+	ProjectIndexFile string `json:"project_index_file"`
+	NewVersionDir    string `json:"new_version_dir"`
+	NewVersionPath   string `json:"new_version_path"`
+}
+
+// resolvePaths computes the paths add would use for the version of tag,
+// the same way addRelease does, without touching the disk
+func resolvePaths(settings Settings, root, project, tag string) (ResolvedPaths, error) {
+	majorMinor, err := extractMajorMinor(tag)
+	if err != nil {
+		return ResolvedPaths{}, err
+	}
+	baseDir := settings.docsDir(root, project)
+	newVersionDir, err := safeVersionDir(baseDir, majorMinor)
+	if err != nil {
+		return ResolvedPaths{}, err
+	}
+	return ResolvedPaths{
+		BaseDir:          baseDir,
+		DataFile:         settings.dataFilePath(root, project),
+		ProjectIndexFile: filepath.Join(baseDir, "_index.md"),
+		NewVersionDir:    newVersionDir,
+		NewVersionPath:   filepath.Join(newVersionDir, "_index.md"),
+	}, nil
+}
+
+// printPaths outputs the resolved paths, one per line or as JSON
+func printPaths(w io.Writer, paths ResolvedPaths, asJSON bool) error {
+	if asJSON {
+		out, err := json.MarshalIndent(paths, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", out)
+		return err
+	}
+	_, err := fmt.Fprintf(w, "baseDir:          %s\ndataFile:         %s\nprojectIndexFile: %s\nnewVersionDir:    %s\nnewVersionPath:   %s\n",
+		paths.BaseDir, paths.DataFile, paths.ProjectIndexFile, paths.NewVersionDir, paths.NewVersionPath)
+	return err
+}
+
+func handlePrintPaths(settings Settings, project string, tag string, asJSON bool) {
+	if project == "" || tag == "" {
+		fmt.Print("Missing project or tag\n")
+		printReleaseUsage()
+		os.Exit(1)
+	}
+	if err := validateProject(project); err != nil {
+		exitWithError(err)
+	}
+
+	paths, err := resolvePaths(settings, "", project, tag)
+	if err != nil {
+		exitWithError(err)
+	}
+	if err := printPaths(os.Stdout, paths, asJSON); err != nil {
+		exitWithError(err)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolvePaths(t *testing.T) {
+	root := filepath.Join("repo", "site")
+	got, err := resolvePaths(Settings{}, root, "eso", "v0.15.3")
+	if err != nil {
+		t.Fatalf("resolvePaths() error = %v", err)
+	}
+	baseDir := filepath.Join(root, "content", "en", "eso-docs")
+	want := ResolvedPaths{
+		BaseDir:          baseDir,
+		DataFile:         filepath.Join(root, "data", "eso_versions.toml"),
+		ProjectIndexFile: filepath.Join(baseDir, "_index.md"),
+		NewVersionDir:    filepath.Join(baseDir, "v0.15"),
+		NewVersionPath:   filepath.Join(baseDir, "v0.15", "_index.md"),
+	}
+	if got != want {
+		t.Errorf("resolvePaths() = %+v, want %+v", got, want)
+	}
+
+	var text bytes.Buffer
+	if err := printPaths(&text, got, false); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text.String(), "newVersionPath:   "+want.NewVersionPath+"\n") {
+		t.Errorf("printPaths() = %q, want the new version path", text.String())
+	}
+
+	var out bytes.Buffer
+	if err := printPaths(&out, got, true); err != nil {
+		t.Fatal(err)
+	}
+	var decoded ResolvedPaths
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || decoded != want {
+		t.Errorf("printPaths() JSON = %s (%v), want %+v", out.String(), err, want)
+	}
+
+	if _, err := resolvePaths(Settings{}, root, "eso", "latest"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("resolvePaths() with an invalid tag error = %v, want ErrInvalid", err)
+	}
+}
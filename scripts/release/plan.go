@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// StepType identifies the kind of change a Step performs
+type StepType string
+
+const (
+	StepDemote         StepType = "demote"
+	StepWriteTOML      StepType = "write-toml"
+	StepMkdir          StepType = "mkdir"
+	StepCopy           StepType = "copy"
+	StepWriteIndex     StepType = "write-index"
+	StepWriteRootIndex StepType = "write-root-index"
+	StepWeights        StepType = "recompute-weights"
+	StepPrune          StepType = "prune"
+	StepArchive        StepType = "archive"
+)
+
+// Step is a single change of a release, in execution order
+type Step struct {
+	Type   StepType `json:"type"`
+	Path   string   `json:"path"`
+	Detail string   `json:"detail,omitempty"`
+}
+
+// Plan is the ordered list of steps a release executes.
+// It is meant for orchestrators displaying progress.
+type Plan struct {
+	Steps []Step
+}
+
+// MarshalJSON encodes the plan as an array of steps
+func (p Plan) MarshalJSON() ([]byte, error) {
+	if p.Steps == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(p.Steps)
+}
+
+// String renders the plan as a numbered list for humans
+func (p Plan) String() string {
+	var b strings.Builder
+	for i, s := range p.Steps {
+		fmt.Fprintf(&b, "%d. %s %s", i+1, s.Type, s.Path)
+		if s.Detail != "" {
+			fmt.Fprintf(&b, " (%s)", s.Detail)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Write outputs the plan as indented JSON
+func (p Plan) Write(w io.Writer) error {
+	out, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", out)
+	return err
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAddReleasePlan(t *testing.T) {
+	root := newTestRepo(t)
+	var out bytes.Buffer
+
+	changes, err := addRelease(AddOptions{
+		Root:              root,
+		Project:           "eso",
+		Tag:               "v0.15.0",
+		TestedK8sVersions: "v1.33",
+		PlanOutput:        &out,
+		DryRun:            true,
+	})
+	if err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	if changes != nil {
+		t.Errorf("addRelease() returned changes in dry run")
+	}
+
+	var steps []Step
+	if err := json.Unmarshal(out.Bytes(), &steps); err != nil {
+		t.Fatalf("plan is not valid JSON: %v\n%s", err, out.String())
+	}
+	var types []StepType
+	for _, s := range steps {
+		types = append(types, s.Type)
+	}
+	want := []StepType{StepDemote, StepWriteTOML, StepMkdir, StepCopy, StepWriteIndex, StepWeights}
+	if !reflect.DeepEqual(types, want) {
+		t.Errorf("plan steps = %v, want %v", types, want)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "content", "en", "eso-docs", "v0.15")); !os.IsNotExist(err) {
+		t.Errorf("dry run created the version folder")
+	}
+}
+
+func TestPlanString(t *testing.T) {
+	p := Plan{Steps: []Step{{Type: StepMkdir, Path: "a"}, {Type: StepCopy, Path: "a", Detail: "from b"}}}
+	want := "1. mkdir a\n2. copy a (from b)\n"
+	if got := p.String(); got != want {
+		t.Errorf("Plan.String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"sort"
+
+	"golang.org/x/mod/semver"
+)
+
+// pruneVersions keeps at most max versions, dropping the lowest semver
+// ones first. Versions marked as latest, and tags that are not semver, are
+// never pruned, so more than max versions may remain. Kept versions stay
+// in their original order.
+func pruneVersions(versions []Version, max int) (kept, pruned []Version) {
+	if max <= 0 || len(versions) <= max {
+		return versions, nil
+	}
+	var candidates []int
+	for i, v := range versions {
+		if !v.Latest && semver.IsValid(normalizeVersion(v.Tag)) {
+			candidates = append(candidates, i)
+		}
+	}
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return compareVersions(versions[candidates[a]].Tag, versions[candidates[b]].Tag) < 0
+	})
+
+	drop := map[int]bool{}
+	for _, i := range candidates {
+		if len(versions)-len(drop) <= max {
+			break
+		}
+		drop[i] = true
+	}
+	for i, v := range versions {
+		if drop[i] {
+			pruned = append(pruned, v)
+		} else {
+			kept = append(kept, v)
+		}
+	}
+	return kept, pruned
+}
+
+// prunedFolders returns the major.minor folders of pruned no kept version
+// uses anymore. pruneVersions only prunes semver tags.
+func prunedFolders(pruned, kept []Version) []string {
+	var folders []string
+	seen := map[string]bool{}
+	for _, v := range pruned {
+		majorMinor := semver.MajorMinor(normalizeVersion(v.Tag))
+		if seen[majorMinor] || isDirectoryUsedByOtherRelease(majorMinor, v.Tag, kept) {
+			continue
+		}
+		seen[majorMinor] = true
+		folders = append(folders, majorMinor)
+	}
+	return folders
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func tags(versions []Version) []string {
+	var out []string
+	for _, v := range versions {
+		out = append(out, v.Tag)
+	}
+	return out
+}
+
+func TestPruneVersions(t *testing.T) {
+	versions := []Version{
+		{Tag: "v0.12.0"},
+		{Tag: "v0.15.0", Latest: true},
+		{Tag: "v0.14.0"},
+		{Tag: "v0.13.0"},
+	}
+	tests := []struct {
+		name       string
+		versions   []Version
+		max        int
+		wantKept   []string
+		wantPruned []string
+	}{
+		{name: "unlimited", versions: versions, max: 0, wantKept: []string{"v0.12.0", "v0.15.0", "v0.14.0", "v0.13.0"}},
+		{name: "under the limit", versions: versions, max: 4, wantKept: []string{"v0.12.0", "v0.15.0", "v0.14.0", "v0.13.0"}},
+		{name: "newest kept in order", versions: versions, max: 2, wantKept: []string{"v0.15.0", "v0.14.0"}, wantPruned: []string{"v0.12.0", "v0.13.0"}},
+		{name: "latest never pruned", versions: versions, max: 1, wantKept: []string{"v0.15.0"}, wantPruned: []string{"v0.12.0", "v0.14.0", "v0.13.0"}},
+		{
+			name:       "old latest preserved",
+			versions:   []Version{{Tag: "v0.15.0"}, {Tag: "v0.14.0"}, {Tag: "v0.10.0", Latest: true}},
+			max:        2,
+			wantKept:   []string{"v0.15.0", "v0.10.0"},
+			wantPruned: []string{"v0.14.0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, pruned := pruneVersions(tt.versions, tt.max)
+			if got := tags(kept); !reflect.DeepEqual(got, tt.wantKept) {
+				t.Errorf("kept %v, want %v", got, tt.wantKept)
+			}
+			if got := tags(pruned); !reflect.DeepEqual(got, tt.wantPruned) {
+				t.Errorf("pruned %v, want %v", got, tt.wantPruned)
+			}
+		})
+	}
+}
+
+func TestAddReleaseMaxVersions(t *testing.T) {
+	root := setupFixture(t)
+	docs := filepath.Join(root, "content", "en", "eso-docs")
+
+	changes, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", ReleaseDate: "2025-03-01", TestedK8sVersions: "v1.33", MaxVersions: 2, PruneContent: true})
+	if err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	if want := []string{"v0.14.0", "v0.13.0"}; !reflect.DeepEqual(changes.Pruned, want) {
+		t.Errorf("Pruned = %v, want %v", changes.Pruned, want)
+	}
+	versions, err := readVersions(filepath.Join(root, "data", "eso_versions.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := tags(versions.Versions), []string{"v0.15.0", "v0.14.1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("versions = %v, want %v", got, want)
+	}
+	if i, err := latestIndex(versions.Versions); err != nil || versions.Versions[i].Tag != "v0.15.0" {
+		t.Errorf("latest = %d (%v), want v0.15.0", i, err)
+	}
+	// v0.14 is still used by v0.14.1
+	if _, err := os.Stat(filepath.Join(docs, "v0.14")); err != nil {
+		t.Errorf("v0.14 was deleted: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(docs, "v0.13")); !os.IsNotExist(err) {
+		t.Errorf("v0.13 was not deleted: %v", err)
+	}
+}
+
+func TestAddReleaseMaxVersionsKeepsContent(t *testing.T) {
+	root := setupFixture(t)
+
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", ReleaseDate: "2025-03-01", TestedK8sVersions: "v1.33", MaxVersions: 2}); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "content", "en", "eso-docs", "v0.13")); err != nil {
+		t.Errorf("v0.13 was deleted without --prune-content: %v", err)
+	}
+}
+
+func TestAddReleasePruneContentRequiresMaxVersions(t *testing.T) {
+	root := setupFixture(t)
+
+	_, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33", PruneContent: true})
+	if !errors.Is(err, ErrInvalid) {
+		t.Errorf("addRelease() error = %v, want ErrInvalid", err)
+	}
+}
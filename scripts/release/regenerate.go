@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+func handleRegenerateIndexes(settings Settings, project string, rootIndex bool) {
+	if project == "" {
+		fmt.Print("Missing project\n")
+		printReleaseUsage()
+		os.Exit(1)
+	}
+
+	if err := regenerateIndexes(settings, "", project, rootIndex); err != nil {
+		exitWithError(err)
+	}
+}
+
+// regenerateIndexes rewrites the landing page of every version folder of
+// the data file from the current template, keeping the extra cascade params
+// of each page, and the root index too when rootIndex is set.
+// Version folders missing on disk, or without a landing page, are skipped
+// with a warning: no content is created or deleted.
+func regenerateIndexes(settings Settings, root string, project string, rootIndex bool) error {
+	if err := validateProject(project); err != nil {
+		return err
+	}
+	baseDir := settings.docsDir(root, project)
+	dataFile := settings.dataFilePath(root, project)
+
+	unlock, err := lockDataFile(dataFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	versions, err := readVersions(dataFile)
+	if err != nil {
+		return err
+	}
+
+	for i, folder := range versionFolders(versions.Versions) {
+		if _, err := os.Stat(filepath.Join(baseDir, folder)); os.IsNotExist(err) {
+			logWarning("skipping %s: no content folder", folder)
+			continue
+		}
+		indexPath := filepath.Join(baseDir, folder, "_index.md")
+		content, err := os.ReadFile(indexPath)
+		if os.IsNotExist(err) {
+			logWarning("skipping %s: no landing page to regenerate", folder)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		text := renderLandingPage(versions.longName(project), project, folder)
+		params, err := customCascadeParams(string(content))
+		if err != nil {
+			return fmt.Errorf("cannot read the cascade params of %s: %w", indexPath, err)
+		}
+		for _, p := range params {
+			text = setCascadeParam(text, p.Key, p.Value)
+		}
+		// The template has no weight: restore the sidebar order, as
+		// recomputeWeights does, before comparing with the current page
+		text = setFrontMatterWeight(text, i+1)
+		if err := checkTOMLFrontMatter(indexPath, text); err != nil {
+			return err
+		}
+		if err := writeIfChanged(settings, indexPath, normalizeMarkdown(text, usesCRLF(string(content)))); err != nil {
+			return err
+		}
+	}
+
+	if rootIndex {
+		if err := writeRootIndex(settings, baseDir, project, versions); err != nil {
+			return err
+		}
+	}
+
+	_, err = settings.recomputeWeights(baseDir, versions)
+	return err
+}
+
+// regenerateRootIndex rewrites the root index of project, leaving the
+// landing pages of its versions alone
+func regenerateRootIndex(settings Settings, root string, project string) error {
+	if err := validateProject(project); err != nil {
+		return err
+	}
+	dataFile := settings.dataFilePath(root, project)
+
+	unlock, err := lockDataFile(dataFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	versions, err := readVersions(dataFile)
+	if err != nil {
+		return err
+	}
+	return writeRootIndex(settings, settings.docsDir(root, project), project, versions)
+}
+
+// writeRootIndex renders the root index of project in baseDir, keeping the
+// line endings of the current one
+func writeRootIndex(settings Settings, baseDir string, project string, versions *VersionsData) error {
+	rootIndexPath := filepath.Join(baseDir, "_index.md")
+	current, err := os.ReadFile(rootIndexPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	text := renderRootIndex(versions.longName(project), project)
+	return writeIfChanged(settings, rootIndexPath, normalizeMarkdown(text, usesCRLF(string(current))))
+}
+
+// writeIfChanged writes content to path unless path already has it, so
+// that retried runs leave the file, and its modification time, untouched
+func writeIfChanged(settings Settings, path string, content string) error {
+	current, err := os.ReadFile(path)
+	if err == nil && string(current) == content {
+		fmt.Printf("%s is already up to date\n", path)
+		return nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := settings.writeGeneratedFile(path, []byte(content)); err != nil {
+		return err
+	}
+	printStep("Regenerated %s", path)
+	return nil
+}
+
+// customCascadeParams returns the string cascade params of a landing page
+// front matter that the template does not render, sorted by key.
+// project_go_version is kept too as it cannot be recomputed offline.
+func customCascadeParams(content string) ([]cascadeParam, error) {
+	lines := strings.SplitAfter(content, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "+++" {
+		return nil, nil
+	}
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "+++" {
+			end = i
+			break
+		}
+	}
+	if end == -1 {
+		return nil, nil
+	}
+
+	var frontMatter struct {
+		Cascade []struct {
+			Params map[string]any `toml:"params"`
+		} `toml:"cascade"`
+	}
+	if _, err := toml.Decode(strings.Join(lines[1:end], ""), &frontMatter); err != nil {
+		return nil, err
+	}
+
+	var params []cascadeParam
+	for _, cascade := range frontMatter.Cascade {
+		for key, value := range cascade.Params {
+			if key == "project" || key == "project_version" {
+				continue
+			}
+			s, ok := value.(string)
+			if !ok {
+				logWarning("dropping cascade param %s, only string values are kept", key)
+				continue
+			}
+			params = append(params, cascadeParam{Key: key, Value: s})
+		}
+	}
+	sort.Slice(params, func(i, j int) bool { return params[i].Key < params[j].Key })
+	return params, nil
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegenerateIndexes(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"data/eso_versions.toml": `[[versions]]
+  tag = "v0.15.0"
+  latest = true
+  release_date = "2025-02-01"
+  tested_k8s_versions = ["v1.33"]
+  end_of_life = ""
+
+[[versions]]
+  tag = "v0.14.0"
+  latest = false
+  release_date = "2025-01-01"
+  tested_k8s_versions = ["v1.32"]
+  end_of_life = ""
+`,
+		"content/en/eso-docs/v0.15/_index.md": `+++
+title = "Old title"
+weight = 7
+
+[[cascade]]
+type = "docs"
+
+  [cascade.params]
+  project = "eso"
+  project_version = "v0.15"
+  support_status = "supported"
+  project_go_version = "1.23.0"
++++
+
+Old body.
+`,
+		"content/en/eso-docs/v0.14/_index.md": "+++\ntitle = \"Old title\"\n+++\n",
+	})
+
+	if err := regenerateIndexes(Settings{}, root, "eso", false); err != nil {
+		t.Fatalf("regenerateIndexes() error = %v", err)
+	}
+
+	golden := map[string]string{
+		"v0.15": `+++
+title = "External-Secrets Operator v0.15 Documentation"
+linkTitle = "v0.15"
+slug = "v0.15"
+sidebar_root_for = "self"
+
+weight = 1
+[[cascade]]
+type = "docs"
+
+  [cascade.params]
+  project = "eso"
+  project_version = "v0.15"
+  project_go_version = "1.23.0"
+  support_status = "supported"
++++
+
+Welcome to the External-Secrets Operator v0.15 documentation.
+`,
+		"v0.14": `+++
+title = "External-Secrets Operator v0.14 Documentation"
+linkTitle = "v0.14"
+slug = "v0.14"
+sidebar_root_for = "self"
+
+weight = 2
+[[cascade]]
+type = "docs"
+
+  [cascade.params]
+  project = "eso"
+  project_version = "v0.14"
++++
+
+Welcome to the External-Secrets Operator v0.14 documentation.
+`,
+	}
+	for folder, want := range golden {
+		got, err := os.ReadFile(filepath.Join(root, "content", "en", "eso-docs", folder, "_index.md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s/_index.md =\n%s\nwant\n%s", folder, got, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "content", "en", "eso-docs", "_index.md")); !os.IsNotExist(err) {
+		t.Errorf("root index was written without rootIndex: %v", err)
+	}
+}
+
+func TestRegenerateIndexesSkipsMissingFolders(t *testing.T) {
+	root := newTestRepo(t)
+
+	if err := regenerateIndexes(Settings{}, root, "eso", true); err != nil {
+		t.Fatalf("regenerateIndexes() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "content", "en", "eso-docs", "v0.14")); !os.IsNotExist(err) {
+		t.Errorf("content folder of v0.14 was created: %v", err)
+	}
+	rootIndex, err := os.ReadFile(filepath.Join(root, "content", "en", "eso-docs", "_index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(rootIndex) != renderRootIndex(projectLongName("eso"), "eso") {
+		t.Errorf("root index was not regenerated:\n%s", rootIndex)
+	}
+}
+
+func TestRegenerateIndexesSkipsFoldersWithoutLandingPage(t *testing.T) {
+	root := newTestRepo(t)
+	writeFiles(t, root, map[string]string{"content/en/eso-docs/v0.14/guide.md": "# Guide\n"})
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	if err := regenerateIndexes(Settings{}, root, "eso", false); err != nil {
+		t.Fatalf("regenerateIndexes() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "content", "en", "eso-docs", "v0.14", "_index.md")); !os.IsNotExist(err) {
+		t.Errorf("landing page of v0.14 was created: %v", err)
+	}
+	if !strings.Contains(logs.String(), "Warning: skipping v0.14: no landing page to regenerate") {
+		t.Errorf("logs = %q, want v0.14 reported as skipped", logs.String())
+	}
+}
+
+func TestRegenerateRootIndexKeepsCRLF(t *testing.T) {
+	root := newTestRepo(t)
+	rootIndexPath := filepath.Join(root, "content", "en", "eso-docs", "_index.md")
+	writeFiles(t, root, map[string]string{"content/en/eso-docs/_index.md": "+++\r\ntitle = \"Old title\"\r\n+++\r\n\r\n\r\n"})
+
+	if err := regenerateRootIndex(Settings{}, root, "eso"); err != nil {
+		t.Fatalf("regenerateRootIndex() error = %v", err)
+	}
+	got, err := os.ReadFile(rootIndexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := normalizeMarkdown(renderRootIndex(projectLongName("eso"), "eso"), true)
+	if string(got) != want {
+		t.Errorf("root index = %q, want %q", got, want)
+	}
+	if strings.Count(string(got), "\n") != strings.Count(string(got), "\r\n") || strings.HasSuffix(string(got), "\r\n\r\n") {
+		t.Errorf("root index = %q, want CRLF line endings and a single trailing newline", got)
+	}
+}
+
+func TestRegenerateIndexesIdempotent(t *testing.T) {
+	root := setupFixture(t)
+	baseDir := filepath.Join(root, "content", "en", "eso-docs")
+	if err := regenerateIndexes(Settings{}, root, "eso", true); err != nil {
+		t.Fatalf("regenerateIndexes() error = %v", err)
+	}
+
+	old := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	paths := []string{filepath.Join(baseDir, "_index.md"), filepath.Join(baseDir, "v0.14", "_index.md"), filepath.Join(baseDir, "v0.13", "_index.md")}
+	before := map[string]string{}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		before[path] = string(content)
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := regenerateIndexes(Settings{}, root, "eso", true); err != nil {
+		t.Fatalf("second regenerateIndexes() error = %v", err)
+	}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !info.ModTime().Equal(old) {
+			t.Errorf("%s was rewritten by the second run, modtime %v", path, info.ModTime())
+		}
+		if content, err := os.ReadFile(path); err != nil || string(content) != before[path] {
+			t.Errorf("%s changed by the second run:\n%s", path, content)
+		}
+	}
+}
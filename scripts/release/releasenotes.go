@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	// githubAPIURL is the GitHub REST API the release notes are read from
+	githubAPIURL = "https://api.github.com"
+	// githubTokenEnv names the variable holding the token authenticating
+	// GitHub API requests, optional but raising the rate limit
+	githubTokenEnv = "GITHUB_TOKEN"
+)
+
+// testedK8sAnnotation matches the machine readable line of release notes
+// listing the tested k8s versions, e.g. "tested-k8s: v1.35,v1.34", also
+// when hidden in an HTML comment
+var testedK8sAnnotation = regexp.MustCompile(`(?mi)^\s*(?:<!--\s*)?tested-k8s\s*:\s*(.*?)\s*(?:-->)?\s*$`)
+
+// parseTestedK8sAnnotation returns the tested k8s versions annotated in a
+// release body, normalized to v1.X, and whether the annotation is present
+func parseTestedK8sAnnotation(body string) ([]string, bool) {
+	m := testedK8sAnnotation.FindStringSubmatch(body)
+	if m == nil {
+		return nil, false
+	}
+	var versions []string
+	for _, version := range strings.FieldsFunc(m[1], func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+		versions = append(versions, normalizeK8sVersion(version))
+	}
+	return versions, true
+}
+
+// fetchReleaseBody returns the notes of the GitHub release of a version of
+// project, authenticated with GITHUB_TOKEN when set
+func (s Settings) fetchReleaseBody(client *http.Client, project string, tag string) (string, error) {
+	repo := projects[project].GitHubRepo
+	if repo == "" {
+		return "", invalidf("project %s has no GitHub repository configured to read its release notes from", project)
+	}
+	url := fmt.Sprintf("%s/repos/%s/releases/tags/%s", githubAPIURL, repo, gitTag(project, tag))
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token := os.Getenv(githubTokenEnv); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := s.doWithRetry(client, req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrFetchRelease, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: %s: HTTP %d", ErrFetchRelease, url, resp.StatusCode)
+	}
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to read response: %w", ErrFetchRelease, err)
+	}
+
+	var release struct {
+		Body string `json:"body"`
+	}
+	if err := json.Unmarshal(content, &release); err != nil {
+		return "", fmt.Errorf("%w: invalid response from %s: %w", ErrFetchRelease, url, err)
+	}
+	return release.Body, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const releaseBody = `## What's Changed
+
+* Bump client-go by @someone in #4242
+
+<!-- tested-k8s: v1.34, 1.33 -->
+
+**Full Changelog**: https://github.com/external-secrets/external-secrets/compare/v0.14.0...v0.15.0
+`
+
+func TestParseTestedK8sAnnotation(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		want      []string
+		wantFound bool
+	}{
+		{name: "html comment", body: releaseBody, want: []string{"v1.34", "v1.33"}, wantFound: true},
+		{name: "plain line", body: "Notes\nTested-K8s: v1.33,v1.32\n", want: []string{"v1.33", "v1.32"}, wantFound: true},
+		{name: "without annotation", body: "## What's Changed\n\nTested on kind.\n"},
+		{name: "empty annotation", body: "tested-k8s:\n", wantFound: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := parseTestedK8sAnnotation(tt.body)
+			if found != tt.wantFound || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTestedK8sAnnotation() = %v, %v, want %v, %v", got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestAddReleaseK8sFromReleaseNotes(t *testing.T) {
+	const releaseURL = "https://api.github.com/repos/external-secrets/external-secrets/releases/tags/v0.15.0"
+	goModURL, err := resolveGoModURL("eso", "v0.15.0", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "annotated", body: releaseBody, want: "v1.34,v1.33"},
+		{name: "not annotated", body: "## What's Changed\n", want: "v1.35"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release, err := json.Marshal(map[string]string{"tag_name": "v0.15.0", "body": tt.body})
+			if err != nil {
+				t.Fatal(err)
+			}
+			original := httpClient
+			t.Cleanup(func() { httpClient = original })
+			httpClient = &http.Client{Transport: routeTransport{
+				releaseURL: string(release),
+				goModURL:   sampleGoMod,
+			}}
+
+			root := newTestRepo(t)
+			changes, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", K8sFromReleaseNotes: true})
+			if err != nil {
+				t.Fatalf("addRelease() error = %v", err)
+			}
+			if got := strings.Join(changes.TestedK8sVersions, ","); got != tt.want {
+				t.Errorf("tested k8s versions = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchReleaseBodyNotFound(t *testing.T) {
+	original := httpClient
+	t.Cleanup(func() { httpClient = original })
+	httpClient = &http.Client{Transport: routeTransport{}}
+
+	if _, err := (Settings{}).fetchReleaseBody(httpClient, "eso", "v0.15.0"); !errors.Is(err, ErrFetchRelease) || exitCode(err) != exitUpstream {
+		t.Errorf("fetchReleaseBody() error = %v, want ErrFetchRelease", err)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"golang.org/x/mod/semver"
+)
+
+func handleRename(settings Settings, project string, from string, to string) {
+	if project == "" || from == "" || to == "" {
+		fmt.Print("Missing project, from or to\n")
+		printReleaseUsage()
+		os.Exit(1)
+	}
+
+	if err := renameRelease(settings, "", project, from, to); err != nil {
+		exitWithError(err)
+	}
+	fmt.Printf("\nVersion %s renamed to %s successfully!\n", from, to)
+}
+
+// renameRelease retags the release from as to: its data file entry and,
+// when the major.minor changes, its content folder and landing page.
+func renameRelease(settings Settings, root string, project string, from string, to string) error {
+	if err := validateProject(project); err != nil {
+		return err
+	}
+	from, to = normalizeVersion(from), normalizeVersion(to)
+	for _, tag := range []string{from, to} {
+		if !semver.IsValid(tag) {
+			return invalidf("Invalid semver tag: %s", tag)
+		}
+	}
+
+	baseDir := settings.docsDir(root, project)
+	dataFile := settings.dataFilePath(root, project)
+
+	unlock, err := lockDataFile(dataFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	versions, err := readVersions(dataFile)
+	if err != nil {
+		return err
+	}
+
+	idx := -1
+	for i := range versions.Versions {
+		if compareVersions(versions.Versions[i].Tag, from) == 0 {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return invalidf("Version %s not found", from)
+	}
+	if existing := findDuplicateTag(to, versions.Versions); existing != "" {
+		return fmt.Errorf("Version %s %w (as %s)", to, ErrAlreadyExists, existing)
+	}
+
+	fromMajorMinor, err := extractMajorMinor(from)
+	if err != nil {
+		return err
+	}
+	toMajorMinor, err := extractMajorMinor(to)
+	if err != nil {
+		return err
+	}
+	fromDir, err := safeVersionDir(baseDir, fromMajorMinor)
+	if err != nil {
+		return err
+	}
+	toDir, err := safeVersionDir(baseDir, toMajorMinor)
+	if err != nil {
+		return err
+	}
+	moveContent := fromDir != toDir
+	if moveContent {
+		if isDirectoryUsedByOtherRelease(fromMajorMinor, from, versions.Versions) {
+			return fmt.Errorf("Directory %s is still used by other releases, cannot rename it", fromDir)
+		}
+		if _, err := os.Lstat(toDir); err == nil {
+			return fmt.Errorf("Directory %s %w", toDir, ErrAlreadyExists)
+		}
+	}
+
+	versions.Versions[idx].Tag = to
+	if err := settings.writeVersions(dataFile, versions); err != nil {
+		return err
+	}
+	printStep("Updated %s (%s -> %s)", dataFile, from, to)
+
+	if !moveContent {
+		return nil
+	}
+
+	if err := os.Rename(fromDir, toDir); err != nil {
+		return fmt.Errorf("Failed to rename %s: %w", fromDir, err)
+	}
+	fmt.Printf("Renamed %s to %s\n", fromDir, toDir)
+
+	indexPath := filepath.Join(toDir, "_index.md")
+	content, err := os.ReadFile(indexPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	re := regexp.MustCompile(regexp.QuoteMeta(fromMajorMinor) + `\b`)
+	if err := settings.writeGeneratedFile(indexPath, []byte(re.ReplaceAllString(string(content), toMajorMinor))); err != nil {
+		return err
+	}
+	printStep("Overwritten %s", indexPath)
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenameRelease(t *testing.T) {
+	tests := []struct {
+		name       string
+		from       string
+		to         string
+		wantLatest bool
+		wantDir    string
+	}{
+		{name: "latest in the same folder", from: "v0.15.0-rc.1", to: "v0.15.0", wantLatest: true, wantDir: "v0.15"},
+		{name: "non latest to another folder", from: "v0.14.0", to: "v0.13.9", wantLatest: false, wantDir: "v0.13"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := newTestRepo(t)
+			if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0-rc.1", TestedK8sVersions: "v1.33"}); err != nil {
+				t.Fatal(err)
+			}
+			baseDir := filepath.Join(root, "content", "en", "eso-docs")
+			if err := os.MkdirAll(filepath.Join(baseDir, "v0.14"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(baseDir, "v0.14", "_index.md"), []byte("+++\ntitle = \"ESO (v0.14)\"\n+++\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := renameRelease(Settings{}, root, "eso", tt.from, tt.to); err != nil {
+				t.Fatalf("renameRelease() error = %v", err)
+			}
+
+			versions, err := readVersions(filepath.Join(root, "data", "eso_versions.toml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var found *Version
+			for i := range versions.Versions {
+				if versions.Versions[i].Tag == tt.from {
+					t.Errorf("%s is still in the data file", tt.from)
+				}
+				if versions.Versions[i].Tag == tt.to {
+					found = &versions.Versions[i]
+				}
+			}
+			if found == nil {
+				t.Fatalf("%s not found in the data file", tt.to)
+			}
+			if found.Latest != tt.wantLatest {
+				t.Errorf("%s latest = %v, want %v", tt.to, found.Latest, tt.wantLatest)
+			}
+			index, err := os.ReadFile(filepath.Join(baseDir, tt.wantDir, "_index.md"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(index), tt.wantDir) {
+				t.Errorf("landing page does not mention %s:\n%s", tt.wantDir, index)
+			}
+		})
+	}
+}
+
+func TestRenameReleaseTargetExists(t *testing.T) {
+	root := newTestRepo(t)
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33"}); err != nil {
+		t.Fatal(err)
+	}
+	err := renameRelease(Settings{}, root, "eso", "v0.14.0", "v0.15.0")
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("renameRelease() error = %v, want already exists", err)
+	}
+}
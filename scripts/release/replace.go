@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// ReplaceOptions are the metadata the replace action overwrites.
+// Empty fields are kept as they are.
+type ReplaceOptions struct {
+	ReleaseDate       string
+	TestedK8sVersions string
+	EndOfLife         string
+}
+
+func handleReplace(settings Settings, project string, tag string, opts ReplaceOptions) {
+	if project == "" || tag == "" {
+		fmt.Print("Missing project or tag\n")
+		printReleaseUsage()
+		os.Exit(1)
+	}
+	if opts == (ReplaceOptions{}) {
+		fmt.Print("Nothing to replace, pass --release-date, --tested-k8s-versions or --end-of-life\n")
+		printReleaseUsage()
+		os.Exit(1)
+	}
+
+	if err := replaceRelease(settings, "", project, tag, opts); err != nil {
+		exitWithError(err)
+	}
+	fmt.Printf("\nVersion %s updated successfully!\n", tag)
+}
+
+func handleSetEOL(settings Settings, project string, tag string, endOfLife string) {
+	if project == "" || tag == "" || endOfLife == "" {
+		fmt.Print("Missing project, tag or end of life\n")
+		printReleaseUsage()
+		os.Exit(1)
+	}
+
+	if err := replaceRelease(settings, "", project, tag, ReplaceOptions{EndOfLife: endOfLife}); err != nil {
+		exitWithError(err)
+	}
+	fmt.Printf("\nEnd of life of %s set to %s\n", tag, endOfLife)
+}
+
+// replaceRelease overwrites the metadata of an existing version in place,
+// without touching its content nor which version is the latest.
+func replaceRelease(settings Settings, root string, project string, tag string, opts ReplaceOptions) error {
+	if err := validateProject(project); err != nil {
+		return err
+	}
+	for _, date := range []string{opts.ReleaseDate, opts.EndOfLife} {
+		if _, err := time.Parse("2006-01-02", date); date != "" && err != nil {
+			return invalidf("invalid date %s, expected YYYY-MM-DD", date)
+		}
+	}
+	var testedK8sVersions []string
+	if opts.TestedK8sVersions != "" {
+		testedK8sVersions = splitK8sVersions(opts.TestedK8sVersions)
+		if len(testedK8sVersions) == 0 {
+			return invalidf("--tested-k8s-versions %q has no version", opts.TestedK8sVersions)
+		}
+		if err := validateTestedK8sVersions(testedK8sVersions, defaultMinK8sMinor, 0); err != nil {
+			return err
+		}
+	}
+
+	dataFile := settings.dataFilePath(root, project)
+	unlock, err := lockDataFile(dataFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	versions, err := readVersions(dataFile)
+	if err != nil {
+		return err
+	}
+
+	idx := -1
+	for i := range versions.Versions {
+		if compareVersions(versions.Versions[i].Tag, tag) == 0 {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return invalidf("Version %s not found", tag)
+	}
+
+	v := &versions.Versions[idx]
+	if opts.ReleaseDate != "" {
+		v.ReleaseDate = opts.ReleaseDate
+	}
+	if testedK8sVersions != nil {
+		v.TestedK8sVersions = testedK8sVersions
+	}
+	if opts.EndOfLife != "" {
+		v.EndOfLife = opts.EndOfLife
+	}
+	// Dates are YYYY-MM-DD so they compare as strings
+	if v.EndOfLife != "" && v.EndOfLife < v.ReleaseDate {
+		return invalidf("end of life %s of %s is earlier than its release date %s", v.EndOfLife, v.Tag, v.ReleaseDate)
+	}
+
+	if err := settings.writeVersions(dataFile, versions); err != nil {
+		return err
+	}
+	printStep("Updated %s", dataFile)
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReplaceRelease(t *testing.T) {
+	root := newTestRepo(t)
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", ReleaseDate: "2025-02-01", TestedK8sVersions: "v1.33"}); err != nil {
+		t.Fatal(err)
+	}
+	dataFile := filepath.Join(root, "data", "eso_versions.toml")
+	before, err := readVersions(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := replaceRelease(Settings{}, root, "eso", "v0.14", ReplaceOptions{ReleaseDate: "2025-01-15", EndOfLife: "2025-07-15"}); err != nil {
+		t.Fatalf("replaceRelease() error = %v", err)
+	}
+
+	after, err := readVersions(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := before.Versions[1]
+	want.ReleaseDate = "2025-01-15"
+	want.EndOfLife = "2025-07-15"
+	if !reflect.DeepEqual(after.Versions[1], want) {
+		t.Errorf("replaced version = %+v, want %+v", after.Versions[1], want)
+	}
+	if !reflect.DeepEqual(after.Versions[0], before.Versions[0]) {
+		t.Errorf("other version changed: %+v, want %+v", after.Versions[0], before.Versions[0])
+	}
+
+	if err := replaceRelease(Settings{}, root, "eso", "v0.15.0", ReplaceOptions{TestedK8sVersions: "v1.33,v1.34"}); err != nil {
+		t.Fatalf("replaceRelease() error = %v", err)
+	}
+	after, err = readVersions(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := after.Versions[0]; !got.Latest || !reflect.DeepEqual(got.TestedK8sVersions, []string{"v1.33", "v1.34"}) || got.ReleaseDate != "2025-02-01" {
+		t.Errorf("replaced latest version = %+v", got)
+	}
+}
+
+func TestReplaceReleaseErrors(t *testing.T) {
+	root := newTestRepo(t)
+	for name, opts := range map[string]ReplaceOptions{
+		"invalid date":        {ReleaseDate: "01/02/2025"},
+		"invalid k8s version": {TestedK8sVersions: "v1.33,1.34"},
+	} {
+		if err := replaceRelease(Settings{}, root, "eso", "v0.14.0", opts); err == nil {
+			t.Errorf("%s: replaceRelease() succeeded, want an error", name)
+		}
+	}
+	if err := replaceRelease(Settings{}, root, "eso", "v0.13.0", ReplaceOptions{ReleaseDate: "2025-01-01"}); err == nil {
+		t.Error("replaceRelease() of a missing version succeeded, want an error")
+	}
+}
+
+func TestSetEndOfLife(t *testing.T) {
+	root := newTestRepo(t)
+	dataFile := filepath.Join(root, "data", "eso_versions.toml")
+	before, err := readVersions(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := replaceRelease(Settings{}, root, "eso", "v0.14", ReplaceOptions{EndOfLife: "2026-01-01"}); err != nil {
+		t.Fatalf("replaceRelease() error = %v", err)
+	}
+	after, err := readVersions(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := before.Versions[0]
+	want.EndOfLife = "2026-01-01"
+	if !reflect.DeepEqual(after.Versions[0], want) {
+		t.Errorf("version = %+v, want only its end of life changed: %+v", after.Versions[0], want)
+	}
+}
+
+func TestSetEndOfLifeBeforeRelease(t *testing.T) {
+	root := newTestRepo(t)
+	dataFile := filepath.Join(root, "data", "eso_versions.toml")
+	original, err := os.ReadFile(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = replaceRelease(Settings{}, root, "eso", "v0.14.0", ReplaceOptions{EndOfLife: "2024-12-31"})
+	if exitCode(err) != exitInvalid {
+		t.Fatalf("replaceRelease() error = %v, want an end of life earlier than the release", err)
+	}
+	if got, err := os.ReadFile(dataFile); err != nil || string(got) != string(original) {
+		t.Errorf("data file changed by a rejected end of life:\n%s", got)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+// Defaults of the --retry-* flags
+const (
+	defaultRetryMax       = 3
+	defaultRetryBaseDelay = time.Second
+	defaultRetryMaxDelay  = 30 * time.Second
+)
+
+// sleep waits between retries, it is replaced in tests
+var sleep = time.Sleep
+
+// validateRetries checks the --retry-* flags
+func (s Settings) validateRetries() error {
+	if s.RetryMax < 0 {
+		return invalidf("invalid --retry-max %d, expected 0 or more", s.RetryMax)
+	}
+	if s.RetryBaseDelay < 0 || s.RetryMaxDelay < 0 {
+		return invalidf("invalid retry delays %s and %s, expected durations of 0 or more", s.RetryBaseDelay, s.RetryMaxDelay)
+	}
+	if s.RetryBaseDelay > s.RetryMaxDelay {
+		return invalidf("--retry-base-delay %s is longer than --retry-max-delay %s", s.RetryBaseDelay, s.RetryMaxDelay)
+	}
+	return nil
+}
+
+// retryDelay returns the wait before the retry following attempt, counted
+// from 0: RetryBaseDelay doubled for each attempt, capped at RetryMaxDelay
+func (s Settings) retryDelay(attempt int) time.Duration {
+	delay := s.RetryBaseDelay
+	for range attempt {
+		if delay >= s.RetryMaxDelay/2 {
+			return s.RetryMaxDelay
+		}
+		delay *= 2
+	}
+	return min(delay, s.RetryMaxDelay)
+}
+
+// retryable reports whether a request failing with resp or err may succeed
+// when sent again: network errors, rate limits and server errors
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// doWithRetry sends req with client, retrying it up to RetryMax times
+// with an exponential backoff while it fails transiently. req must not have
+// a body, as the fetches of this tool do.
+func (s Settings) doWithRetry(client *http.Client, req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := client.Do(req)
+		if attempt >= s.RetryMax || !retryable(resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		delay := s.retryDelay(attempt)
+		logWarning("request to %s failed, retrying in %s", req.URL, delay)
+		sleep(delay)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordSleeps records the waits between retries instead of sleeping
+func recordSleeps(t *testing.T) *[]time.Duration {
+	t.Helper()
+	original := sleep
+	t.Cleanup(func() { sleep = original })
+	var slept []time.Duration
+	sleep = func(d time.Duration) { slept = append(slept, d) }
+	return &slept
+}
+
+// statusTransport answers with the statuses in turn, then with the last one
+type statusTransport struct {
+	statuses []int
+	requests int
+}
+
+func (s *statusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	status := s.statuses[min(s.requests, len(s.statuses)-1)]
+	s.requests++
+	return &http.Response{
+		StatusCode: status,
+		Body:       io.NopCloser(strings.NewReader(sampleGoMod)),
+		Request:    req,
+	}, nil
+}
+
+func useStatusTransport(t *testing.T, statuses ...int) *statusTransport {
+	t.Helper()
+	transport := &statusTransport{statuses: statuses}
+	original := httpClient
+	httpClient = &http.Client{Transport: transport}
+	t.Cleanup(func() { httpClient = original })
+	return transport
+}
+
+func TestRetryBackoff(t *testing.T) {
+	tests := []struct {
+		name      string
+		max       int
+		base, cap time.Duration
+		statuses  []int
+		wantSlept []time.Duration
+		wantErr   bool
+	}{
+		{
+			name:      "doubles up to the max delay",
+			max:       5,
+			base:      time.Second,
+			cap:       5 * time.Second,
+			statuses:  []int{http.StatusBadGateway},
+			wantSlept: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second},
+			wantErr:   true,
+		},
+		{
+			name:      "stops once it succeeds",
+			max:       3,
+			base:      100 * time.Millisecond,
+			cap:       time.Second,
+			statuses:  []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK},
+			wantSlept: []time.Duration{100 * time.Millisecond, 200 * time.Millisecond},
+		},
+		{
+			name:     "no retries",
+			max:      0,
+			base:     time.Second,
+			cap:      time.Second,
+			statuses: []int{http.StatusInternalServerError},
+			wantErr:  true,
+		},
+		{
+			name:     "client errors are not retried",
+			max:      3,
+			base:     time.Second,
+			cap:      time.Second,
+			statuses: []int{http.StatusNotFound},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slept := recordSleeps(t)
+			transport := useStatusTransport(t, tt.statuses...)
+			settings := Settings{RetryMax: tt.max, RetryBaseDelay: tt.base, RetryMaxDelay: tt.cap}
+
+			_, err := settings.fetchGoMod(httpClient, "https://example.com/go.mod")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fetchGoMod() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(*slept, tt.wantSlept) {
+				t.Errorf("slept %v, want %v", *slept, tt.wantSlept)
+			}
+			if want := len(tt.wantSlept) + 1; transport.requests != want {
+				t.Errorf("sent %d requests, want %d", transport.requests, want)
+			}
+		})
+	}
+}
+
+func TestRetryNetworkErrors(t *testing.T) {
+	slept := recordSleeps(t)
+	original := httpClient
+	httpClient = &http.Client{Transport: failingTransport{}}
+	t.Cleanup(func() { httpClient = original })
+	settings := Settings{RetryMax: 2, RetryBaseDelay: time.Second, RetryMaxDelay: time.Minute}
+
+	_, err := settings.fetchGoMod(httpClient, "https://example.com/go.mod")
+	if !errors.Is(err, ErrFetchGoMod) {
+		t.Fatalf("fetchGoMod() error = %v, want ErrFetchGoMod", err)
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second}; !slices.Equal(*slept, want) {
+		t.Errorf("slept %v, want %v", *slept, want)
+	}
+}
+
+func TestValidateRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		max       int
+		base, cap time.Duration
+		wantErr   bool
+	}{
+		{name: "defaults", max: defaultRetryMax, base: defaultRetryBaseDelay, cap: defaultRetryMaxDelay},
+		{name: "disabled", max: 0, base: 0, cap: 0},
+		{name: "equal delays", max: 1, base: time.Second, cap: time.Second},
+		{name: "negative count", max: -1, base: time.Second, cap: time.Second, wantErr: true},
+		{name: "negative delay", max: 1, base: -time.Second, cap: time.Second, wantErr: true},
+		{name: "base above max", max: 1, base: time.Minute, cap: time.Second, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := Settings{RetryMax: tt.max, RetryBaseDelay: tt.base, RetryMaxDelay: tt.cap}
+			err := settings.validateRetries()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateRetries() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalid) {
+				t.Errorf("validateRetries() error = %v, want ErrInvalid", err)
+			}
+		})
+	}
+}
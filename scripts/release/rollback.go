@@ -0,0 +1,162 @@
+package main
+
+import (
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// rollback restores the repository as it was before a release started
+// changing it, so a failing step does not leave it inconsistent.
+type rollback struct {
+	// settings give the modes of the restored data file and folders
+	settings     Settings
+	dataFile     string
+	originalData []byte
+	// originalJSON is the JSON mirror of dataFile, nil when there was none
+	originalJSON []byte
+	createdPaths []string
+	// savedFiles are the files and symlinks to restore, by path
+	savedFiles map[string]savedFile
+	savedOrder []string
+}
+
+// savedFile is the content of a file, or the target of a symlink, before
+// the release changed it
+type savedFile struct {
+	mode    fs.FileMode
+	content []byte
+	target  string
+}
+
+// newRollback snapshots the content of dataFile (and its JSON mirror) in memory
+func newRollback(settings Settings, dataFile string) (*rollback, error) {
+	original, err := os.ReadFile(dataFile)
+	if err != nil {
+		return nil, err
+	}
+	originalJSON, err := os.ReadFile(versionsJSONFile(dataFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return &rollback{settings: settings, dataFile: dataFile, originalData: original, originalJSON: originalJSON, savedFiles: map[string]savedFile{}}, nil
+}
+
+// removeIfCreated registers path (a file or a directory) for removal on
+// rollback, unless it already exists: pre-existing content is never deleted.
+// It must be called before path gets created.
+func (r *rollback) removeIfCreated(path string) {
+	if _, err := os.Lstat(path); os.IsNotExist(err) {
+		r.createdPaths = append(r.createdPaths, path)
+	}
+}
+
+// restoreIfChanged snapshots path, a file or a symlink, to restore it on
+// rollback, or registers it for removal when it does not exist yet. It must
+// be called before path gets overwritten or created.
+func (r *rollback) restoreIfChanged(path string) error {
+	if _, saved := r.savedFiles[path]; saved {
+		return nil
+	}
+	info, err := os.Lstat(path)
+	switch {
+	case os.IsNotExist(err):
+		r.createdPaths = append(r.createdPaths, path)
+		return nil
+	case err != nil:
+		return err
+	}
+	saved := savedFile{mode: info.Mode()}
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		if saved.target, err = os.Readlink(path); err != nil {
+			return err
+		}
+	case info.Mode().IsRegular():
+		if saved.content, err = os.ReadFile(path); err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+	r.savedFiles[path] = saved
+	r.savedOrder = append(r.savedOrder, path)
+	return nil
+}
+
+// restoreTree snapshots the files of the existing folder dst, and those the
+// copy of src will create or overwrite in it. A folder the release creates
+// needs removeIfCreated only.
+func (r *rollback) restoreTree(src, dst string, excludeDirs []string) error {
+	if _, err := os.Lstat(dst); os.IsNotExist(err) {
+		return nil
+	}
+	// The files of dst not in src may still be removed, e.g. by --strip-drafts
+	err := walkTree(osFS{}, dst, nil, func(path, rel string, info fs.FileInfo) error {
+		if info.IsDir() {
+			return nil
+		}
+		return r.restoreIfChanged(path)
+	})
+	if err != nil {
+		return err
+	}
+	return walkTree(osFS{}, src, excludeDirs, func(path, rel string, info fs.FileInfo) error {
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			r.removeIfCreated(target)
+			return nil
+		}
+		return r.restoreIfChanged(target)
+	})
+}
+
+// run undoes the registered changes. Failures are logged and do not stop
+// the rest of the rollback.
+func (r *rollback) run() {
+	// Files saved once created by the release go along with their folder
+	for _, path := range r.savedOrder {
+		log.Printf("Rolling back: restoring %s", path)
+		if err := r.savedFiles[path].restore(path, r.settings.dirMode()); err != nil {
+			log.Printf("Rollback failed to restore %s: %v", path, err)
+		}
+	}
+	for i := len(r.createdPaths) - 1; i >= 0; i-- {
+		log.Printf("Rolling back: removing %s", r.createdPaths[i])
+		if err := os.RemoveAll(r.createdPaths[i]); err != nil {
+			log.Printf("Rollback failed to remove %s: %v", r.createdPaths[i], err)
+		}
+	}
+	log.Printf("Rolling back: restoring %s", r.dataFile)
+	if err := os.WriteFile(r.dataFile, r.originalData, r.settings.fileMode()); err != nil {
+		log.Printf("Rollback failed to restore %s: %v", r.dataFile, err)
+	}
+
+	jsonFile := versionsJSONFile(r.dataFile)
+	if r.originalJSON == nil {
+		if err := os.Remove(jsonFile); err != nil && !os.IsNotExist(err) {
+			log.Printf("Rollback failed to remove %s: %v", jsonFile, err)
+		}
+	} else if err := os.WriteFile(jsonFile, r.originalJSON, r.settings.fileMode()); err != nil {
+		log.Printf("Rollback failed to restore %s: %v", jsonFile, err)
+	}
+}
+
+// restore writes the saved file or symlink back to path, with its mode,
+// creating its missing parent folders with dirMode
+func (s savedFile) restore(path string, dirMode fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), dirMode); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(path); err != nil {
+		return err
+	}
+	if s.mode&os.ModeSymlink != 0 {
+		return os.Symlink(s.target, path)
+	}
+	if err := os.WriteFile(path, s.content, s.mode.Perm()); err != nil {
+		return err
+	}
+	return os.Chmod(path, s.mode.Perm())
+}
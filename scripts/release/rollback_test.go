@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAddReleaseRollsBackWhenCopyFails(t *testing.T) {
+	root := newTestRepo(t)
+	dataFile := filepath.Join(root, "data", "eso_versions.toml")
+	before, err := os.ReadFile(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	copyContent = func(src, dst string, opts CopyOptions) error { return errors.New("disk full") }
+	t.Cleanup(func() { copyContent = CopyDirWithOptions })
+
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33"}); err == nil {
+		t.Fatal("addRelease() expected an error")
+	}
+
+	after, err := os.ReadFile(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(before) {
+		t.Errorf("data file not restored:\n%s", after)
+	}
+	if _, err := os.Stat(filepath.Join(root, "content", "en", "eso-docs", "v0.15")); !os.IsNotExist(err) {
+		t.Errorf("freshly created version folder was not removed")
+	}
+}
+
+func TestAddReleaseRollbackKeepsExistingFolder(t *testing.T) {
+	root := newTestRepo(t)
+	existing := filepath.Join(root, "content", "en", "eso-docs", "v0.15", "kept.md")
+	if err := os.MkdirAll(filepath.Dir(existing), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(existing, []byte("kept"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	copyContent = func(src, dst string, opts CopyOptions) error { return errors.New("disk full") }
+	t.Cleanup(func() { copyContent = CopyDirWithOptions })
+
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33"}); err == nil {
+		t.Fatal("addRelease() expected an error")
+	}
+	if _, err := os.Stat(existing); err != nil {
+		t.Errorf("pre-existing content was removed: %v", err)
+	}
+}
+
+func TestAddReleaseRollbackRestoresOverwrittenPages(t *testing.T) {
+	// v0.14.2 is copied over the existing v0.14 folder, v0.15.0 moves the
+	// sidebar weights of the older versions. Then the archive, one of the
+	// last steps, fails.
+	for _, tag := range []string{"v0.14.2", "v0.15.0"} {
+		t.Run(tag, func(t *testing.T) {
+			root := setupFixture(t)
+			baseDir := filepath.Join(root, "content", "en", "eso-docs")
+			before := snapshotTree(t, baseDir)
+
+			archive := filepath.Join(root, "missing", "docs.zip")
+			_, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: tag, TestedK8sVersions: "v1.32", Force: true, ArchiveZip: archive})
+			if err == nil {
+				t.Fatal("addRelease() expected an error")
+			}
+
+			after := snapshotTree(t, baseDir)
+			for path, want := range before {
+				if after[path] != want {
+					t.Errorf("%s = %q after the rollback, want %q", path, after[path], want)
+				}
+			}
+			for path := range after {
+				if _, ok := before[path]; !ok {
+					t.Errorf("%s was left after the rollback", path)
+				}
+			}
+		})
+	}
+}
+
+func TestAddReleaseRollbackRestoresOverwrittenArchive(t *testing.T) {
+	root := newTestRepo(t)
+	archive := filepath.Join(root, "docs.zip")
+	// A folder where the manifest goes makes the step after the archive fail
+	writeFiles(t, root, map[string]string{
+		"docs.zip": "previous bundle",
+		"content/en/eso-docs/v0.15/" + manifestFile + "/kept.md": "kept",
+	})
+
+	_, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33", Force: true, Manifest: true, ArchiveZip: archive})
+	if err == nil {
+		t.Fatal("addRelease() expected an error")
+	}
+	if got, err := os.ReadFile(archive); err != nil || string(got) != "previous bundle" {
+		t.Errorf("archive not restored by the rollback, got %d bytes (%v)", len(got), err)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"io/fs"
+	"time"
+)
+
+// Defaults of the permissions of the files and folders the tool creates
+const (
+	defaultFileMode fs.FileMode = 0644
+	defaultDirMode  fs.FileMode = 0755
+)
+
+// Settings are the flags shared by the release actions, shaping the files
+// they write and how they fetch. The zero value detects the format of the
+// data files, creates files and folders with the default permissions and
+// fetches without cache nor retries.
+type Settings struct {
+	// DataFormat forces the format of the data files. When empty, the
+	// format is detected from the data file extension.
+	DataFormat string
+	// CompactTOML writes each version of the TOML data files as an inline
+	// table on its own line, so that a change shows as a one line diff
+	CompactTOML bool
+	// EmitJSON creates the JSON mirror of the data files
+	EmitJSON bool
+	// ArtifactsDir gathers the opt-in side artifacts of a run (plan,
+	// summary, JSON mirror of the data file) when set, e.g. to archive them
+	ArtifactsDir string
+	// ContentAlias replaces the project in the docs folder and URLs, when set
+	ContentAlias string
+	// Backup saves the data file it changes as <data file>.bak first,
+	// BackupCleanup removes the backups once the run succeeded
+	Backup        bool
+	BackupCleanup bool
+	// FileMode and DirMode are the permissions of the files and folders
+	// the tool creates, the defaults when zero. Copied files keep the ones
+	// of their source.
+	FileMode fs.FileMode
+	DirMode  fs.FileMode
+	// CacheDir stores the fetched go.mod files, in the user cache directory
+	// when empty. A cached go.mod is used for CacheTTL instead of fetching
+	// it again, the cache is disabled when it is not positive or with
+	// NoCache.
+	CacheDir string
+	CacheTTL time.Duration
+	NoCache  bool
+	// RetryMax is how many times a failed request is retried, none when 0.
+	// RetryBaseDelay is the wait before the first retry, doubled for each
+	// next one up to RetryMaxDelay.
+	RetryMax       int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
+	// backups maps the data files backed up during the run to their backup,
+	// when set, so that each one is backed up once
+	backups map[string]string
+}
+
+// validate checks the settings given on the command line
+func (s Settings) validate() error {
+	if err := validateDataFormat(s.DataFormat); err != nil {
+		return err
+	}
+	if err := s.validateRetries(); err != nil {
+		return err
+	}
+	if s.ContentAlias != "" && !bareKey.MatchString(s.ContentAlias) {
+		return invalidf("invalid content alias %q, only letters, digits, '_' and '-' are allowed", s.ContentAlias)
+	}
+	return nil
+}
+
+// fileMode returns the permissions of the files the tool creates
+func (s Settings) fileMode() fs.FileMode {
+	if s.FileMode == 0 {
+		return defaultFileMode
+	}
+	return s.FileMode
+}
+
+// dirMode returns the permissions of the folders the tool creates
+func (s Settings) dirMode() fs.FileMode {
+	if s.DirMode == 0 {
+		return defaultDirMode
+	}
+	return s.DirMode
+}
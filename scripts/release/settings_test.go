@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"time"
+)
+
+func TestSettingsValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings Settings
+		wantErr  bool
+	}{
+		{name: "zero value", settings: Settings{}},
+		{name: "flags", settings: Settings{DataFormat: formatYAML, ContentAlias: "external-secrets", RetryMax: 3, RetryBaseDelay: time.Second, RetryMaxDelay: time.Minute}},
+		{name: "unknown data format", settings: Settings{DataFormat: "json"}, wantErr: true},
+		{name: "invalid content alias", settings: Settings{ContentAlias: "../docs"}, wantErr: true},
+		{name: "negative retries", settings: Settings{RetryMax: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.settings.validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalid) {
+				t.Errorf("validate() error = %v, want ErrInvalid", err)
+			}
+		})
+	}
+}
+
+func TestSettingsModes(t *testing.T) {
+	if got := (Settings{}).fileMode(); got != defaultFileMode {
+		t.Errorf("fileMode() = %v, want %v", got, defaultFileMode)
+	}
+	if got := (Settings{}).dirMode(); got != defaultDirMode {
+		t.Errorf("dirMode() = %v, want %v", got, defaultDirMode)
+	}
+	settings := Settings{FileMode: 0600, DirMode: 0700}
+	if got := settings.fileMode(); got != fs.FileMode(0600) {
+		t.Errorf("fileMode() = %v, want 0600", got)
+	}
+	if got := settings.dirMode(); got != fs.FileMode(0700) {
+		t.Errorf("dirMode() = %v, want 0700", got)
+	}
+}
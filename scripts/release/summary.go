@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Changeset summarises what a successful add changed in the repository.
+// Paths are relative to the repository root and use forward slashes.
+type Changeset struct {
+	Project           string   `json:"project"`
+	FilesCreated      []string `json:"files_created"`
+	FilesModified     []string `json:"files_modified"`
+	PreviousLatest    string   `json:"previous_latest"`
+	NewLatest         string   `json:"new_latest"`
+	TestedK8sVersions []string `json:"tested_k8s_versions"`
+	// K8sAdded and K8sRemoved are the tested k8s versions added and dropped
+	// since the previous latest version
+	K8sAdded   []string `json:"k8s_added"`
+	K8sRemoved []string `json:"k8s_removed"`
+	// GoVersion is the go directive of the release's go.mod, when known
+	GoVersion string `json:"go_version,omitempty"`
+	// DataFile is the versions data file that was updated
+	DataFile string `json:"data_file"`
+	// PreviousKeptLatest is set when the previous latest was not demoted
+	PreviousKeptLatest bool `json:"previous_kept_latest,omitempty"`
+	// Pruned lists the versions removed by --max-versions
+	Pruned []string `json:"pruned,omitempty"`
+	// Beta is set when NewLatest was added as the beta version, the
+	// previous latest staying the latest
+	Beta bool `json:"beta,omitempty"`
+	// VersionDir is the content folder of the version
+	VersionDir string `json:"version_dir,omitempty"`
+	// FoldersRemoved lists the content folders deleted by --prune-content
+	FoldersRemoved []string `json:"folders_removed,omitempty"`
+}
+
+// recordModified adds path to the list of modified files
+func (c *Changeset) recordModified(root, path string) {
+	c.FilesModified = append(c.FilesModified, relativeToRoot(root, path))
+}
+
+// recordRemoved drops path from the created and modified files, for a
+// file removed after the copy
+func (c *Changeset) recordRemoved(root, path string) {
+	rel := relativeToRoot(root, path)
+	c.FilesCreated = slices.DeleteFunc(c.FilesCreated, func(f string) bool { return f == rel })
+	c.FilesModified = slices.DeleteFunc(c.FilesModified, func(f string) bool { return f == rel })
+}
+
+// recordCopy records, for each file of src, whether copying it into dst
+// creates a new file or overwrites an existing one.
+// It must be called before the copy happens.
+func (c *Changeset) recordCopy(root, src, dst string, excludeDirs []string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if d.IsDir() {
+			if isExcludedDir(src, path, excludeDirs) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if _, err := os.Lstat(target); err == nil {
+			c.FilesModified = append(c.FilesModified, relativeToRoot(root, target))
+		} else {
+			c.FilesCreated = append(c.FilesCreated, relativeToRoot(root, target))
+		}
+		return nil
+	})
+}
+
+// Markdown renders the changeset so it can be pasted into a PR body
+func (c *Changeset) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Release %s (%s)\n\n", c.NewLatest, c.Project)
+	if c.Beta {
+		fmt.Fprintf(&b, "- New beta: `%s`\n", c.NewLatest)
+	} else {
+		fmt.Fprintf(&b, "- New latest: `%s`\n", c.NewLatest)
+	}
+	switch {
+	case c.PreviousLatest != "" && c.Beta:
+		fmt.Fprintf(&b, "- Latest: `%s` (unchanged)\n", c.PreviousLatest)
+	case c.PreviousLatest != "" && c.PreviousKeptLatest:
+		fmt.Fprintf(&b, "- Previous latest: `%s` (still latest, not demoted)\n", c.PreviousLatest)
+	case c.PreviousLatest != "":
+		fmt.Fprintf(&b, "- Previous latest: `%s` (demoted)\n", c.PreviousLatest)
+	}
+	fmt.Fprintf(&b, "- Tested Kubernetes versions: %s\n", strings.Join(c.TestedK8sVersions, ", "))
+	if len(c.K8sAdded) > 0 || len(c.K8sRemoved) > 0 {
+		var changes []string
+		if len(c.K8sAdded) > 0 {
+			changes = append(changes, "added "+strings.Join(c.K8sAdded, ", "))
+		}
+		if len(c.K8sRemoved) > 0 {
+			changes = append(changes, "dropped "+strings.Join(c.K8sRemoved, ", "))
+		}
+		fmt.Fprintf(&b, "- Kubernetes changes since `%s`: %s\n", c.PreviousLatest, strings.Join(changes, "; "))
+	}
+	if c.GoVersion != "" {
+		fmt.Fprintf(&b, "- Go version: %s\n", c.GoVersion)
+	}
+	if len(c.Pruned) > 0 {
+		fmt.Fprintf(&b, "- Pruned: `%s`\n", strings.Join(c.Pruned, "`, `"))
+	}
+
+	writeFileList(&b, "Files created", c.FilesCreated)
+	writeFileList(&b, "Files modified", c.FilesModified)
+	return b.String()
+}
+
+func writeFileList(b *strings.Builder, title string, files []string) {
+	if len(files) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n### %s\n\n", title)
+	for _, f := range files {
+		fmt.Fprintf(b, "- `%s`\n", f)
+	}
+}
+
+// relativeToRoot returns path relative to root with forward slashes,
+// falling back to path itself when it cannot be made relative.
+func relativeToRoot(root, path string) string {
+	if root == "" {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// githubOutputEnv names the file GitHub Actions reads step outputs from
+const githubOutputEnv = "GITHUB_OUTPUT"
+
+// writeGitHubOutput appends the changeset as step outputs to the file
+// named by GITHUB_OUTPUT, created with the file mode of settings when
+// missing. It does nothing outside of GitHub Actions.
+func (c *Changeset) writeGitHubOutput(settings Settings) error {
+	path := os.Getenv(githubOutputEnv)
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, settings.fileMode())
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, "new_version=%s\nprevious_latest=%s\ndata_file=%s\n", c.NewLatest, c.PreviousLatest, c.DataFile)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestRepo creates a minimal website checkout for the eso project with
+// v0.14.0 as latest and returns its root.
+func newTestRepo(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"data/eso_versions.toml": `[[versions]]
+  tag = "v0.14.0"
+  latest = true
+  release_date = "2025-01-01"
+  tested_k8s_versions = ["v1.32"]
+  end_of_life = ""
+`,
+		"content/en/eso-docs/_index.md":                "+++\ntitle = \"Operator Documentation\"\n+++\n",
+		"content/en/eso-docs/unreleased/_index.md":     "+++\ntitle = \"ESO Documentation (Unreleased)\"\n+++\n",
+		"content/en/eso-docs/unreleased/guide/page.md": "# Guide\n",
+	}
+	writeFiles(t, root, files)
+	return root
+}
+
+func TestAddReleaseSummary(t *testing.T) {
+	root := newTestRepo(t)
+
+	changes, err := addRelease(AddOptions{
+		Root:              root,
+		Project:           "eso",
+		Tag:               "v0.15.0",
+		ReleaseDate:       "2025-02-01",
+		TestedK8sVersions: "v1.32,v1.33",
+	})
+	if err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+
+	md := changes.Markdown()
+	for _, want := range []string{
+		"New latest: `v0.15.0`",
+		"Previous latest: `v0.14.0` (demoted)",
+		"v1.32, v1.33",
+		"Kubernetes changes since `v0.14.0`: added v1.33",
+		"`data/eso_versions.toml`",
+		"`content/en/eso-docs/v0.15/guide/page.md`",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown() does not contain %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestWriteGitHubOutput(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "output")
+	if err := os.WriteFile(output, []byte("existing=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(githubOutputEnv, output)
+
+	changes := &Changeset{NewLatest: "v0.15.0", PreviousLatest: "v0.14.0", DataFile: "data/eso_versions.toml"}
+	if err := changes.writeGitHubOutput(Settings{}); err != nil {
+		t.Fatalf("writeGitHubOutput() error = %v", err)
+	}
+
+	got, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "existing=1\nnew_version=v0.15.0\nprevious_latest=v0.14.0\ndata_file=data/eso_versions.toml\n"
+	if string(got) != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestWriteGitHubOutputOutsideActions(t *testing.T) {
+	t.Setenv(githubOutputEnv, "")
+	if err := (&Changeset{NewLatest: "v0.15.0"}).writeGitHubOutput(Settings{}); err != nil {
+		t.Errorf("writeGitHubOutput() error = %v", err)
+	}
+}
+
+func TestWriteGitHubOutputMode(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "output")
+	t.Setenv(githubOutputEnv, output)
+
+	if err := (&Changeset{NewLatest: "v0.15.0"}).writeGitHubOutput(Settings{FileMode: 0600}); err != nil {
+		t.Fatalf("writeGitHubOutput() error = %v", err)
+	}
+	info, err := os.Stat(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode().Perm(); got != 0600 {
+		t.Errorf("mode of %s = %v, want the --file-mode 0600", output, got)
+	}
+}
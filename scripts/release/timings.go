@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Phases of an add run measured by Timings
+const (
+	PhaseFetch     = "fetch"
+	PhaseRead      = "read"
+	PhaseWriteTOML = "write-toml"
+	PhaseMkdir     = "mkdir"
+	PhaseCopy      = "copy"
+	PhaseIndex     = "index"
+)
+
+// Timings records how long the phases of a run took and what the copy
+// did, to tune the copy of large documentation trees.
+// A nil Timings records nothing.
+type Timings struct {
+	Phases map[string]time.Duration
+	Copy   CopyStats
+	// order lists the phases as they were first executed
+	order []string
+}
+
+// start starts timing phase, until the returned function is called
+func (t *Timings) start(phase string) func() {
+	if t == nil {
+		return func() {}
+	}
+	begin := time.Now()
+	return func() {
+		if t.Phases == nil {
+			t.Phases = map[string]time.Duration{}
+		}
+		if _, seen := t.Phases[phase]; !seen {
+			t.order = append(t.order, phase)
+		}
+		t.Phases[phase] += time.Since(begin)
+	}
+}
+
+// copyStats returns the stats the copy should fill, nil without Timings
+func (t *Timings) copyStats() *CopyStats {
+	if t == nil {
+		return nil
+	}
+	return &t.Copy
+}
+
+// timingsJSON is the JSON form of Timings, durations in milliseconds
+type timingsJSON struct {
+	PhasesMs    map[string]float64 `json:"phases_ms"`
+	FilesCopied int                `json:"files_copied"`
+	BytesCopied int64              `json:"bytes_copied"`
+}
+
+// json returns the JSON form of t, nil without Timings
+func (t *Timings) json() *timingsJSON {
+	if t == nil {
+		return nil
+	}
+	out := &timingsJSON{PhasesMs: map[string]float64{}, FilesCopied: t.Copy.Files, BytesCopied: t.Copy.Bytes}
+	for phase, d := range t.Phases {
+		out.PhasesMs[phase] = float64(d.Microseconds()) / 1000
+	}
+	return out
+}
+
+// print writes the duration of each phase, in execution order, and the
+// copy stats
+func (t *Timings) print(w io.Writer) error {
+	if t == nil || len(t.order) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprint(w, "\nTimings:\n"); err != nil {
+		return err
+	}
+	for _, phase := range t.order {
+		if _, err := fmt.Fprintf(w, "  %-10s %s\n", phase, t.Phases[phase].Round(time.Microsecond)); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "  copied %d files, %d bytes\n", t.Copy.Files, t.Copy.Bytes)
+	return err
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAddReleaseTimings(t *testing.T) {
+	useFakeTransport(t, sampleGoMod)
+	root := newTestRepo(t)
+	timings := &Timings{}
+
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", Timings: timings}); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	for _, phase := range []string{PhaseFetch, PhaseRead, PhaseWriteTOML, PhaseMkdir, PhaseCopy, PhaseIndex} {
+		if _, ok := timings.Phases[phase]; !ok {
+			t.Errorf("timings have no %s phase: %v", phase, timings.Phases)
+		}
+	}
+	if timings.Copy.Files != 2 || timings.Copy.Bytes == 0 {
+		t.Errorf("copy stats = %+v, want the 2 files of unreleased", timings.Copy)
+	}
+
+	var out bytes.Buffer
+	if err := timings.print(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "\nTimings:\n  fetch ") || !strings.Contains(out.String(), "copied 2 files") {
+		t.Errorf("print() =\n%s\nwant the phases in execution order and the copy stats", out.String())
+	}
+	if got := timings.json(); len(got.PhasesMs) != 6 || got.FilesCopied != 2 {
+		t.Errorf("json() = %+v, want 6 phases and 2 files copied", got)
+	}
+}
+
+func TestAddReleaseTimingsNoCopy(t *testing.T) {
+	root := newTestRepo(t)
+	timings := &Timings{}
+
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33", NoCopy: true, Timings: timings}); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	if len(timings.Phases) != 2 {
+		t.Errorf("timings = %v, want only the read and write-toml phases", timings.Phases)
+	}
+	for _, phase := range []string{PhaseRead, PhaseWriteTOML} {
+		if _, ok := timings.Phases[phase]; !ok {
+			t.Errorf("timings have no %s phase: %v", phase, timings.Phases)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// stdinName is the data file argument of validate reading stdin
+const stdinName = "-"
+
+func handleValidate(settings Settings, project string, repair bool, args []string) {
+	if project == "" {
+		fmt.Print("Missing project\n")
+		printReleaseUsage()
+		os.Exit(1)
+	}
+
+	if len(args) == 1 && args[0] == stdinName {
+		if err := validateVersionsReader(settings, os.Stdin, project, repair); err != nil {
+			exitWithError(err)
+		}
+		fmt.Printf("%s versions from stdin are valid\n", project)
+		return
+	}
+	if len(args) > 0 {
+		exitWithError(invalidf("unexpected arguments %q, only - reads the data file from stdin", args))
+	}
+
+	if err := validateDataFile(settings, "", project, repair); err != nil {
+		exitWithError(err)
+	}
+	fmt.Printf("%s versions are valid\n", project)
+}
+
+// validateVersionsReader checks the data file of project read from r, e.g.
+// a proposed file before writing it. It cannot be repaired.
+func validateVersionsReader(settings Settings, r io.Reader, project string, repair bool) error {
+	if err := validateProject(project); err != nil {
+		return err
+	}
+	if repair {
+		return invalidf("--repair cannot rewrite the data file read from stdin")
+	}
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("cannot read the data file from stdin: %w", err)
+	}
+	// Without an extension to detect it from, stdin is TOML unless forced
+	format := settings.DataFormat
+	if format == "" {
+		format = formatTOML
+	}
+	versions, err := parseVersions("stdin", format, content)
+	if err != nil {
+		return err
+	}
+	if err := checkSingleLatest(versions.Versions); err != nil {
+		return fmt.Errorf("stdin: %w", err)
+	}
+	return nil
+}
+
+// validateDataFile checks the project data file for inconsistencies.
+// With repair, the fixable ones are fixed and the file is rewritten.
+func validateDataFile(settings Settings, root string, project string, repair bool) error {
+	if err := validateProject(project); err != nil {
+		return err
+	}
+	dataFile := settings.dataFilePath(root, project)
+
+	unlock, err := lockDataFile(dataFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	versions, err := readVersions(dataFile)
+	if err != nil {
+		return err
+	}
+
+	if err := checkSingleLatest(versions.Versions); err != nil {
+		if !repair {
+			return fmt.Errorf("%s: %w", dataFile, err)
+		}
+		kept, cleared := repairLatest(versions.Versions)
+		if err := settings.writeVersions(dataFile, versions); err != nil {
+			return err
+		}
+		fmt.Printf("Repaired %s: kept %s as latest, cleared %s\n", dataFile, kept, strings.Join(cleared, ", "))
+	}
+	return nil
+}
+
+// latestTags returns the tags of the versions marked as latest
+func latestTags(versions []Version) []string {
+	var tags []string
+	for _, v := range versions {
+		if v.Latest {
+			tags = append(tags, v.Tag)
+		}
+	}
+	return tags
+}
+
+// checkSingleLatest fails when more than one version is marked as latest
+func checkSingleLatest(versions []Version) error {
+	if tags := latestTags(versions); len(tags) > 1 {
+		return invalidf("multiple versions are marked as latest (%s), run with --repair to keep only the highest one", strings.Join(tags, ", "))
+	}
+	return nil
+}
+
+// repairLatest keeps the highest semver tag among the versions marked as
+// latest and clears the others. It returns the kept and cleared tags.
+func repairLatest(versions []Version) (kept string, cleared []string) {
+	keptIdx := -1
+	for i, v := range versions {
+		if !v.Latest {
+			continue
+		}
+		if keptIdx == -1 || compareVersions(v.Tag, versions[keptIdx].Tag) > 0 {
+			keptIdx = i
+		}
+	}
+	for i := range versions {
+		if versions[i].Latest && i != keptIdx {
+			versions[i].Latest = false
+			cleared = append(cleared, versions[i].Tag)
+		}
+	}
+	if keptIdx == -1 {
+		return "", nil
+	}
+	return versions[keptIdx].Tag, cleared
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeTwoLatests makes v0.13.0 latest in addition to v0.14.0
+func writeTwoLatests(t *testing.T, root string) {
+	t.Helper()
+	if err := (Settings{}).writeVersions(filepath.Join(root, "data", "eso_versions.toml"), &VersionsData{Versions: []Version{
+		{Tag: "v0.13.0", Latest: true},
+		{Tag: "v0.14.0", Latest: true},
+		{Tag: "v0.12.0"},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateDataFileMultipleLatest(t *testing.T) {
+	root := newTestRepo(t)
+	writeTwoLatests(t, root)
+	dataFile := filepath.Join(root, "data", "eso_versions.toml")
+	before, err := os.ReadFile(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = validateDataFile(Settings{}, root, "eso", false)
+	if err == nil || !strings.Contains(err.Error(), "v0.13.0, v0.14.0") {
+		t.Fatalf("validateDataFile() error = %v, want multiple latest error", err)
+	}
+	after, err := os.ReadFile(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(before) != string(after) {
+		t.Error("validation without repair changed the data file")
+	}
+
+	if err := validateDataFile(Settings{}, root, "eso", true); err != nil {
+		t.Fatalf("validateDataFile() with repair error = %v", err)
+	}
+	versions, err := readVersions(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := latestTags(versions.Versions); !reflect.DeepEqual(got, []string{"v0.14.0"}) {
+		t.Errorf("latest after repair = %v, want [v0.14.0]", got)
+	}
+}
+
+func TestAddReleaseMultipleLatest(t *testing.T) {
+	root := newTestRepo(t)
+	writeTwoLatests(t, root)
+
+	_, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33"})
+	if err == nil || !strings.Contains(err.Error(), "multiple versions are marked as latest") {
+		t.Fatalf("addRelease() error = %v, want multiple latest error", err)
+	}
+
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.33", Repair: true}); err != nil {
+		t.Fatalf("addRelease() with repair error = %v", err)
+	}
+	versions, err := readVersions(filepath.Join(root, "data", "eso_versions.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := latestTags(versions.Versions); !reflect.DeepEqual(got, []string{"v0.15.0"}) {
+		t.Errorf("latest after add = %v, want [v0.15.0]", got)
+	}
+}
+
+func TestValidateVersionsReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		repair  bool
+		wantErr string
+	}{
+		{name: "valid", input: "[[versions]]\ntag = \"v0.15.0\"\nlatest = true\n\n[[versions]]\ntag = \"v0.14.0\"\n"},
+		{name: "multiple latest", input: "[[versions]]\ntag = \"v0.15.0\"\nlatest = true\n\n[[versions]]\ntag = \"v0.14.0\"\nlatest = true\n", wantErr: "v0.15.0, v0.14.0"},
+		{name: "no versions", input: "", wantErr: "no versions"},
+		{name: "not toml", input: "versions = [", wantErr: "cannot parse stdin"},
+		{name: "repair", input: "[[versions]]\ntag = \"v0.15.0\"\nlatest = true\n", repair: true, wantErr: "--repair"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateVersionsReader(Settings{}, strings.NewReader(tt.input), "eso", tt.repair)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validateVersionsReader() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validateVersionsReader() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
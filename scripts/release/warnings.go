@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// warningList collects the warnings of a run, so that they are printed
+// together at its end instead of getting lost in the progress output.
+// A nil list only logs them.
+type warningList struct {
+	warnings []string
+}
+
+// add logs a warning and collects it
+func (l *warningList) add(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	logWarning("%s", msg)
+	if l != nil {
+		l.warnings = append(l.warnings, msg)
+	}
+}
+
+// list returns the collected warnings, never nil so it encodes as a JSON array
+func (l *warningList) list() []string {
+	if l == nil || l.warnings == nil {
+		return []string{}
+	}
+	return l.warnings
+}
+
+// print writes the collected warnings as a block, or nothing without warnings
+func (l *warningList) print(w io.Writer) error {
+	warnings := l.list()
+	if len(warnings) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintf(w, "\nWarnings (%d):\n", len(warnings)); err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		if _, err := fmt.Fprintf(w, "- %s\n", warning); err != nil {
+			return err
+		}
+	}
+	return nil
+}
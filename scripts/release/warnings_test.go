@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAddReleaseCollectsWarnings(t *testing.T) {
+	useFakeTransport(t, sampleGoMod)
+	root := newTestRepo(t)
+	warnings := &warningList{}
+	opts := AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", TestedK8sVersions: "v1.32,v1.33", CheckK8sWindow: true, NoCopy: true, Warnings: warnings}
+
+	if _, err := addRelease(opts); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	got := warnings.list()
+	if len(got) != 2 || !strings.Contains(got[0], "v1.35") || !strings.Contains(got[1], "does not exist") {
+		t.Fatalf("warnings = %q, want the k8s mismatch and the missing content", got)
+	}
+
+	var out bytes.Buffer
+	if err := warnings.print(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "\nWarnings (2):\n- ") || strings.Count(out.String(), "\n- ") != 2 {
+		t.Errorf("print() = %q, want a block of 2 warnings", out.String())
+	}
+
+	encoded, err := json.Marshal(addResult{Warnings: warnings.list()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Warnings []string `json:"warnings"`
+	}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded.Warnings, got) {
+		t.Errorf("JSON warnings = %q, want %q", decoded.Warnings, got)
+	}
+}
+
+func TestWarningListEmpty(t *testing.T) {
+	var warnings *warningList
+	var out bytes.Buffer
+	if err := warnings.print(&out); err != nil || out.Len() != 0 {
+		t.Errorf("print() = %q, %v, want nothing", out.String(), err)
+	}
+	encoded, err := json.Marshal(addResult{Warnings: warnings.list()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(encoded), `"warnings":[]`) {
+		t.Errorf("JSON = %s, want an empty warnings array", encoded)
+	}
+}
+
+func TestAddReleaseProgressOut(t *testing.T) {
+	useFakeTransport(t, sampleGoMod)
+	root := newTestRepo(t)
+	var progress bytes.Buffer
+	original := progressOut
+	progressOut = &progress
+	t.Cleanup(func() { progressOut = original })
+	warnings := &warningList{}
+
+	if _, err := addRelease(AddOptions{Root: root, Project: "eso", Tag: "v0.15.0", Warnings: warnings}); err != nil {
+		t.Fatalf("addRelease() error = %v", err)
+	}
+	// Under --json, stdout only carries the result
+	for _, want := range []string{"Current latest: v0.14.0", "New version: v0.15.0", "Copying unreleased content"} {
+		if !strings.Contains(progress.String(), want) {
+			t.Errorf("progress = %q, want %q", progress.String(), want)
+		}
+	}
+	if got := warnings.list(); len(got) != 1 || !strings.Contains(got[0], "did not receive the list of the tested k8s versions") {
+		t.Errorf("warnings = %q, want the missing tested k8s versions reported", got)
+	}
+}
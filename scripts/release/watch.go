@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func handleWatch(settings Settings, project string) {
+	if project == "" {
+		fmt.Print("Missing project\n")
+		printReleaseUsage()
+		os.Exit(1)
+	}
+	if err := validateProject(project); err != nil {
+		exitWithError(err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		exitWithError(err)
+	}
+	// Editors often replace the file rather than write it, so watch its
+	// folder and filter the events on its name
+	dataFile := settings.dataFilePath("", project)
+	if err := watcher.Add(filepath.Dir(dataFile)); err != nil {
+		exitWithError(err)
+	}
+
+	// Closing the watcher on interrupt closes its channels, ending the watch
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		watcher.Close()
+	}()
+
+	fmt.Printf("Watching %s, press Ctrl+C to stop\n", dataFile)
+	if err := watchVersions(settings, "", project, watcher.Events, watcher.Errors); err != nil {
+		exitWithError(err)
+	}
+}
+
+// watchVersions regenerates the root index of project each time an event
+// on its data file changes the latest version. The landing pages of the
+// versions are left alone. It runs until events is closed. A data file that does
+// not parse, e.g. while being edited, is reported and skipped.
+func watchVersions(settings Settings, root string, project string, events <-chan fsnotify.Event, errs <-chan error) error {
+	dataFile := settings.dataFilePath(root, project)
+	latest, err := latestTag(dataFile)
+	if err != nil {
+		logWarning("%v", err)
+	}
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) != filepath.Clean(dataFile) || !event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+				continue
+			}
+			tag, err := latestTag(dataFile)
+			if err != nil {
+				logWarning("%v", err)
+				continue
+			}
+			if tag == latest {
+				continue
+			}
+			printStep("Latest version changed from %s to %s", latest, tag)
+			if err := regenerateRootIndex(settings, root, project); err != nil {
+				logWarning("failed to regenerate the root index: %v", err)
+				continue
+			}
+			latest = tag
+		case err, ok := <-errs:
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("failed to watch %s: %w", dataFile, err)
+		}
+	}
+}
+
+// latestTag returns the tag of the latest version of the data file
+func latestTag(dataFile string) (string, error) {
+	versions, err := readVersions(dataFile)
+	if err != nil {
+		return "", err
+	}
+	i, err := latestIndex(versions.Versions)
+	if err != nil {
+		return "", err
+	}
+	return versions.Versions[i].Tag, nil
+}
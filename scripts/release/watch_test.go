@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestWatchVersions(t *testing.T) {
+	root := newTestRepo(t)
+	dataFile := filepath.Join(root, "data", "eso_versions.toml")
+	rootIndexPath := filepath.Join(root, "content", "en", "eso-docs", "_index.md")
+	original, err := os.ReadFile(rootIndexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only the root index is regenerated, not the version landing pages
+	landingPage := filepath.Join(root, "content", "en", "eso-docs", "v0.15", "_index.md")
+	writeFiles(t, root, map[string]string{"content/en/eso-docs/v0.15/_index.md": "Hand written page\n"})
+
+	events := make(chan fsnotify.Event)
+	done := make(chan error)
+	go func() { done <- watchVersions(Settings{}, root, "eso", events, make(chan error)) }()
+
+	// Neither other files nor edits keeping the latest regenerate anything.
+	// Each send waits for the previous event to be handled.
+	events <- fsnotify.Event{Name: filepath.Join(root, "data", "reloader_versions.toml"), Op: fsnotify.Write}
+	events <- fsnotify.Event{Name: dataFile, Op: fsnotify.Write}
+	events <- fsnotify.Event{Name: dataFile, Op: fsnotify.Chmod}
+	if got, _ := os.ReadFile(rootIndexPath); string(got) != string(original) {
+		t.Errorf("root index regenerated without a latest change:\n%s", got)
+	}
+
+	if err := os.WriteFile(dataFile, []byte(`[[versions]]
+  tag = "v0.15.0"
+  latest = true
+  release_date = "2025-02-01"
+  tested_k8s_versions = ["v1.33"]
+  end_of_life = ""
+
+[[versions]]
+  tag = "v0.14.0"
+  latest = false
+  release_date = "2025-01-01"
+  tested_k8s_versions = ["v1.32"]
+  end_of_life = ""
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	events <- fsnotify.Event{Name: dataFile, Op: fsnotify.Write}
+	close(events)
+	if err := <-done; err != nil {
+		t.Fatalf("watchVersions() error = %v", err)
+	}
+	got, err := os.ReadFile(rootIndexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := renderRootIndex(projectLongName("eso"), "eso"); string(got) != want {
+		t.Errorf("root index =\n%s\nwant it regenerated:\n%s", got, want)
+	}
+	if got, _ := os.ReadFile(landingPage); string(got) != "Hand written page\n" {
+		t.Errorf("landing page of v0.15 was rewritten:\n%s", got)
+	}
+}